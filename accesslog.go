@@ -0,0 +1,92 @@
+package tgproxy
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+)
+
+// accessRecord собирает данные строки журнала доступа, которые становятся известны во вложенных middleware.
+type accessRecord struct {
+	principal string
+}
+
+type accessRecordKey struct{}
+
+// requestMiddleware принимает X-Request-ID клиента или создаёт новый, кладёт его в контекст (логгер добавляет его
+// к каждой записи) и возвращает в ответе. По завершении пишет одну строку журнала доступа.
+func (p *Proxy) requestMiddleware(next http.HandlerFunc) (handler http.HandlerFunc) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		requestID := helpers.RequestID(r.Header.Get(headerRequestID))
+		record := &accessRecord{}
+		ctx := context.WithValue(helpers.WithRequestID(r.Context(), requestID), accessRecordKey{}, record)
+
+		w.Header().Set(headerRequestID, requestID)
+		sw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		defer func() {
+			route := r.Pattern
+			if _, pattern, found := strings.Cut(route, " "); found {
+				route = pattern
+			}
+			logAccess(ctx, routerNetHTTP, r.Method, r.URL.Path, route, sw.statusCode, sw.bytes, time.Since(startTime), record)
+		}()
+		next(sw, r.WithContext(ctx))
+	}
+}
+
+func (p *Proxy) requestFiberMiddleware(c *fiber.Ctx) (err error) {
+
+	startTime := time.Now()
+	requestID := helpers.RequestID(c.Get(headerRequestID))
+	record := &accessRecord{}
+	ctx := context.WithValue(helpers.WithRequestID(c.UserContext(), requestID), accessRecordKey{}, record)
+	c.SetUserContext(ctx)
+	c.Set(headerRequestID, requestID)
+
+	err = c.Next()
+
+	// тело потока не читаем: его размер известен из Content-Length
+	bytes := int64(len(c.Response().Body()))
+	if c.Response().IsBodyStream() {
+		bytes = int64(c.Response().Header.ContentLength())
+	}
+	logAccess(ctx, routerFiber, c.Method(), c.Path(), c.Route().Path, fiberStatusCode(c, err), bytes, time.Since(startTime), record)
+	return
+}
+
+// recordPrincipal запоминает клиента, прошедшего авторизацию, если провайдер умеет его назвать.
+func recordPrincipal(ctx context.Context, auth AuthProvider, header http.Header) {
+
+	record, ok := ctx.Value(accessRecordKey{}).(*accessRecord)
+	if !ok {
+		return
+	}
+	if provider, ok := auth.(PrincipalProvider); ok {
+		record.principal = provider.Principal(header)
+	}
+}
+
+func logAccess(ctx context.Context, router string, method string, path string, route string, statusCode int, bytes int64, duration time.Duration, record *accessRecord) {
+
+	args := []any{
+		slog.String(helpers.LogKeyRouter, router),
+		slog.String(helpers.LogKeyMethod, method),
+		slog.String(helpers.LogKeyPath, path),
+		slog.String(helpers.LogKeyRoute, route),
+		slog.Int(helpers.LogKeyStatusCode, statusCode),
+		slog.Int64(helpers.LogKeyBytes, bytes),
+		slog.Duration(helpers.LogKeyDuration, duration),
+	}
+	if record.principal != "" {
+		args = append(args, slog.String(helpers.LogKeyPrincipal, record.principal))
+	}
+	slog.InfoContext(ctx, "Request completed", args...)
+}
@@ -14,6 +14,12 @@ type FiberAuthProvider interface {
 	AuthorizeFiber(c *fiber.Ctx) (err error)
 }
 
+// PrincipalProvider — необязательное расширение AuthProvider: называет клиента, прошедшего авторизацию,
+// для журнала доступа. Вызывается только после успешной проверки.
+type PrincipalProvider interface {
+	Principal(header http.Header) (principal string)
+}
+
 type UnifiedAuthProvider interface {
 	AuthProvider
 	FiberAuthProvider
@@ -0,0 +1,44 @@
+package loopback
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+// Bus доставляет события подписчикам того же процесса синхронно, в момент Publish.
+// Несколько движков с общим Bus ведут себя как реплики с общей шиной — удобно для тестов.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []func(ctx context.Context, event domain.Invalidation)
+}
+
+func NewBus() (b *Bus) {
+
+	return &Bus{}
+}
+
+func (b *Bus) Publish(ctx context.Context, event domain.Invalidation) (err error) {
+
+	b.mu.RLock()
+	handlers := make([]func(ctx context.Context, event domain.Invalidation), len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+
+	return
+}
+
+func (b *Bus) Subscribe(handler func(ctx context.Context, event domain.Invalidation)) (err error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+
+	return
+}
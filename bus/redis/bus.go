@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+const defaultChannel = "tg-proxy:invalidations"
+
+type message struct {
+	Origin string `json:"origin"`
+	Kind   string `json:"kind"`
+	Alias  string `json:"alias,omitempty"`
+}
+
+// Bus рассылает события через Redis pub/sub. Pub/sub не хранит сообщения: пока подписка
+// переподключается, события теряются, и реплика догоняет изменения по истечении TTL кеша.
+type Bus struct {
+	client  *redis.Client
+	channel string
+	mu      sync.Mutex
+	subs    []*redis.PubSub
+}
+
+func NewBus(client *redis.Client, opts ...BusOption) (b *Bus) {
+
+	b = &Bus{
+		client:  client,
+		channel: defaultChannel,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return
+}
+
+func (b *Bus) Publish(ctx context.Context, event domain.Invalidation) (err error) {
+
+	var data []byte
+	if data, err = json.Marshal(message{Origin: event.Origin, Kind: string(event.Kind), Alias: event.Alias}); err != nil {
+		err = fmt.Errorf("failed to marshal invalidation: %w", err)
+		return
+	}
+
+	if err = b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		err = fmt.Errorf("failed to publish invalidation: %w", err)
+		return
+	}
+
+	return
+}
+
+// Subscribe дожидается подтверждения подписки и обрабатывает сообщения в отдельной горутине до Close.
+func (b *Bus) Subscribe(handler func(ctx context.Context, event domain.Invalidation)) (err error) {
+
+	ctx := context.Background()
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	if _, err = pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		err = fmt.Errorf("failed to subscribe to invalidations: %w", err)
+		return
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, pubsub)
+	b.mu.Unlock()
+
+	go b.listen(ctx, pubsub, handler)
+
+	return
+}
+
+func (b *Bus) Close() (err error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, pubsub := range b.subs {
+		if closeErr := pubsub.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close subscription: %w", closeErr)
+		}
+	}
+	b.subs = nil
+
+	return
+}
+
+func (b *Bus) listen(ctx context.Context, pubsub *redis.PubSub, handler func(ctx context.Context, event domain.Invalidation)) {
+
+	for msg := range pubsub.Channel() {
+		var doc message
+		if err := json.Unmarshal([]byte(msg.Payload), &doc); err != nil {
+			slog.WarnContext(ctx, "Failed to decode invalidation",
+				slog.String(helpers.LogKeyAction, helpers.ActionInvalidateCache),
+				slog.Any(helpers.LogKeyError, err),
+			)
+			continue
+		}
+		handler(ctx, domain.Invalidation{Origin: doc.Origin, Kind: domain.InvalidationKind(doc.Kind), Alias: doc.Alias})
+	}
+}
@@ -0,0 +1,10 @@
+package redis
+
+type BusOption func(*Bus)
+
+// Channel задаёт канал pub/sub; реплики одной инсталляции должны использовать один канал.
+func Channel(channel string) (opt BusOption) {
+	return func(b *Bus) {
+		b.channel = channel
+	}
+}
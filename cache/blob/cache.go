@@ -0,0 +1,447 @@
+package blob
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+const (
+	blobsDir = "blobs"
+	refsDir  = "refs"
+	tmpDir   = "tmp"
+	refExt   = ".json"
+
+	defaultMaxBytes int64 = 10 << 30
+)
+
+// ref связывает (alias, version, filename) с содержимым по его sha256.
+type ref struct {
+	Alias       string    `json:"alias"`
+	Version     string    `json:"version"`
+	Filename    string    `json:"filename"`
+	Digest      string    `json:"digest"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ETag        string    `json:"etag,omitempty"`
+	ModTime     time.Time `json:"mod_time"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type blobEntry struct {
+	digest string
+	size   int64
+	refs   map[string]struct{}
+}
+
+// Cache — файловый кеш с адресацией по содержимому: blobs/<xx>/<sha256> хранит данные,
+// refs/<key>.json — ссылку на них по (alias, version, filename). Одинаковые файлы разных версий хранятся один раз.
+// Время модификации блоба используется как отметка последнего доступа, чтобы порядок LRU переживал перезапуск.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	refs  map[string]ref
+	blobs map[string]*list.Element
+	lru   *list.List
+	size  int64
+}
+
+func NewCache(dir string, opts ...CacheOption) (c *Cache, err error) {
+
+	c = &Cache{
+		dir:      dir,
+		maxBytes: defaultMaxBytes,
+		refs:     make(map[string]ref),
+		blobs:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for _, sub := range []string{blobsDir, refsDir, tmpDir} {
+		if err = os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create blob cache directory: %w", err)
+		}
+	}
+
+	if err = c.load(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (c *Cache) OpenFile(ctx context.Context, alias string, version string, filename string) (body io.ReadSeekCloser, info domain.FileInfo, found bool, err error) {
+
+	key := refKey(alias, version, filename)
+
+	c.mu.Lock()
+	r, exists := c.refs[key]
+	if !exists {
+		c.mu.Unlock()
+		return
+	}
+	el, exists := c.blobs[r.Digest]
+	if !exists {
+		c.removeRefLocked(key)
+		c.mu.Unlock()
+		return
+	}
+	c.lru.MoveToFront(el)
+	c.mu.Unlock()
+
+	path := c.blobPath(r.Digest)
+	var file *os.File
+	if file, err = os.Open(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// блоб удалён с диска в обход индекса или вытеснен между поиском и открытием
+			c.mu.Lock()
+			if el, exists = c.blobs[r.Digest]; exists {
+				c.removeBlobLocked(el)
+			}
+			c.mu.Unlock()
+			err = nil
+			return
+		}
+		return
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	body = file
+	info = domain.FileInfo{
+		Digest:      r.Digest,
+		Size:        r.Size,
+		ContentType: r.ContentType,
+		ETag:        r.ETag,
+		ModTime:     r.ModTime,
+	}
+	found = true
+	return
+}
+
+// PutFile читает body до конца и сохраняет содержимое; ошибка чтения отменяет запись.
+// Из info используются ContentType, ETag и ModTime, дайджест и размер вычисляются по содержимому.
+func (c *Cache) PutFile(ctx context.Context, alias string, version string, filename string, info domain.FileInfo, body io.Reader) (err error) {
+
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(filepath.Join(c.dir, tmpDir), "blob-*"); err != nil {
+		return
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	hasher := sha256.New()
+	var size int64
+	size, err = io.Copy(io.MultiWriter(tmp, hasher), body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	if c.maxBytes > 0 && size > c.maxBytes {
+		_ = os.Remove(tmpPath)
+		return
+	}
+
+	digest := hex.EncodeToString(hasher.Sum(nil))
+	blobPath := c.blobPath(digest)
+	if err = os.MkdirAll(filepath.Dir(blobPath), 0o755); err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	r := ref{
+		Alias:       alias,
+		Version:     version,
+		Filename:    filename,
+		Digest:      digest,
+		Size:        size,
+		ContentType: info.ContentType,
+		ETag:        info.ETag,
+		ModTime:     info.ModTime,
+		CreatedAt:   now,
+	}
+	if r.ModTime.IsZero() {
+		r.ModTime = now
+	}
+	key := refKey(alias, version, filename)
+
+	// блоб и ссылка появляются на диске под той же блокировкой, под которой их удаляют вытеснение и DeleteFiles,
+	// иначе параллельное удаление того же дайджеста может стереть только что переименованный файл
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, stored := c.blobs[digest]
+	if stored {
+		// такое содержимое уже лежит в кеше — копия не нужна
+		_ = os.Remove(tmpPath)
+	} else if err = os.Rename(tmpPath, blobPath); err != nil {
+		return
+	}
+	if err = c.writeRef(key, r); err != nil {
+		if !stored {
+			_ = os.Remove(blobPath)
+		}
+		return
+	}
+
+	c.addRefLocked(key, r)
+	c.evictLocked()
+
+	return
+}
+
+// DeleteFiles удаляет все ссылки проекта; блобы без ссылок удаляются вместе с ними.
+func (c *Cache) DeleteFiles(ctx context.Context, alias string) (err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, r := range c.refs {
+		if r.Alias == alias {
+			c.removeRefLocked(key)
+		}
+	}
+
+	return
+}
+
+// Fits сообщает, примет ли PutFile файл такого размера.
+func (c *Cache) Fits(size int64) (ok bool) {
+
+	return c.maxBytes <= 0 || size <= c.maxBytes
+}
+
+// Size — суммарный размер файлов в кеше в байтах.
+func (c *Cache) Size() (size int64) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+func (c *Cache) addRefLocked(key string, r ref) {
+
+	if old, exists := c.refs[key]; exists && old.Digest != r.Digest {
+		c.detachRefLocked(key, old.Digest)
+	}
+	c.refs[key] = r
+
+	el, exists := c.blobs[r.Digest]
+	if !exists {
+		el = c.lru.PushFront(&blobEntry{
+			digest: r.Digest,
+			size:   r.Size,
+			refs:   make(map[string]struct{}),
+		})
+		c.blobs[r.Digest] = el
+		c.size += r.Size
+	}
+	el.Value.(*blobEntry).refs[key] = struct{}{}
+	c.lru.MoveToFront(el)
+}
+
+func (c *Cache) removeRefLocked(key string) {
+
+	r, exists := c.refs[key]
+	if !exists {
+		return
+	}
+	delete(c.refs, key)
+	_ = os.Remove(c.refPath(key))
+	c.detachRefLocked(key, r.Digest)
+}
+
+func (c *Cache) detachRefLocked(key string, digest string) {
+
+	el, exists := c.blobs[digest]
+	if !exists {
+		return
+	}
+	entry := el.Value.(*blobEntry)
+	delete(entry.refs, key)
+	if len(entry.refs) == 0 {
+		c.removeBlobLocked(el)
+	}
+}
+
+func (c *Cache) removeBlobLocked(el *list.Element) {
+
+	entry := el.Value.(*blobEntry)
+	c.lru.Remove(el)
+	delete(c.blobs, entry.digest)
+	c.size -= entry.size
+	_ = os.Remove(c.blobPath(entry.digest))
+
+	for key := range entry.refs {
+		delete(c.refs, key)
+		_ = os.Remove(c.refPath(key))
+	}
+}
+
+func (c *Cache) evictLocked() {
+
+	if c.maxBytes <= 0 {
+		return
+	}
+
+	for c.size > c.maxBytes && c.lru.Len() > 1 {
+		el := c.lru.Back()
+		entry := el.Value.(*blobEntry)
+		slog.Debug("Evicting file from blob cache",
+			slog.String(helpers.LogKeyDigest, entry.digest),
+			slog.Int64(helpers.LogKeySize, entry.size),
+		)
+		c.removeBlobLocked(el)
+	}
+}
+
+func (c *Cache) load() (err error) {
+
+	tmpEntries, _ := os.ReadDir(filepath.Join(c.dir, tmpDir))
+	for _, entry := range tmpEntries {
+		_ = os.RemoveAll(filepath.Join(c.dir, tmpDir, entry.Name()))
+	}
+
+	type blobFile struct {
+		digest  string
+		size    int64
+		modTime time.Time
+	}
+	var files []blobFile
+
+	root := filepath.Join(c.dir, blobsDir)
+	if err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) (err error) {
+		if walkErr != nil || d.IsDir() {
+			return walkErr
+		}
+		if len(d.Name()) != sha256.Size*2 {
+			_ = os.Remove(path)
+			return
+		}
+		var info fs.FileInfo
+		if info, err = d.Info(); err != nil {
+			return
+		}
+		files = append(files, blobFile{digest: d.Name(), size: info.Size(), modTime: info.ModTime()})
+		return
+	}); err != nil {
+		return fmt.Errorf("failed to scan blob cache: %w", err)
+	}
+
+	// самые давно использованные оказываются в конце списка
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		c.blobs[f.digest] = c.lru.PushFront(&blobEntry{
+			digest: f.digest,
+			size:   f.size,
+			refs:   make(map[string]struct{}),
+		})
+		c.size += f.size
+	}
+
+	var refEntries []os.DirEntry
+	if refEntries, err = os.ReadDir(filepath.Join(c.dir, refsDir)); err != nil {
+		return fmt.Errorf("failed to scan blob cache refs: %w", err)
+	}
+	for _, entry := range refEntries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), refExt) {
+			continue
+		}
+		key := strings.TrimSuffix(entry.Name(), refExt)
+		path := filepath.Join(c.dir, refsDir, entry.Name())
+
+		var r ref
+		data, readErr := os.ReadFile(path)
+		if readErr != nil || json.Unmarshal(data, &r) != nil {
+			_ = os.Remove(path)
+			continue
+		}
+		el, exists := c.blobs[r.Digest]
+		if !exists {
+			_ = os.Remove(path)
+			continue
+		}
+		c.refs[key] = r
+		el.Value.(*blobEntry).refs[key] = struct{}{}
+	}
+
+	for _, el := range c.blobs {
+		if len(el.Value.(*blobEntry).refs) == 0 {
+			c.removeBlobLocked(el)
+		}
+	}
+
+	c.evictLocked()
+	return nil
+}
+
+func (c *Cache) writeRef(key string, r ref) (err error) {
+
+	var data []byte
+	if data, err = json.Marshal(r); err != nil {
+		return
+	}
+
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(filepath.Join(c.dir, tmpDir), "ref-*"); err != nil {
+		return
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, c.refPath(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return
+}
+
+func (c *Cache) blobPath(digest string) (path string) {
+
+	return filepath.Join(c.dir, blobsDir, digest[:2], digest)
+}
+
+func (c *Cache) refPath(key string) (path string) {
+
+	return filepath.Join(c.dir, refsDir, key+refExt)
+}
+
+func refKey(alias string, version string, filename string) (key string) {
+
+	sum := sha256.Sum256([]byte(alias + "\x00" + version + "\x00" + filename))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,158 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+func putFile(t *testing.T, c *Cache, version string, content string) {
+
+	t.Helper()
+
+	if err := c.PutFile(context.Background(), "tool", version, "tool.bin", domain.FileInfo{}, strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cachedFile(t *testing.T, c *Cache, version string) (content string, found bool) {
+
+	t.Helper()
+
+	body, _, found, err := c.OpenFile(context.Background(), "tool", version, "tool.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		return
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), true
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+
+	c, err := NewCache(t.TempDir(), MaxBytes(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	putFile(t, c, "v1", "aaaa")
+	putFile(t, c, "v2", "bbbb")
+	// чтение v1 делает его самым свежим — вытеснен будет v2
+	if _, found := cachedFile(t, c, "v1"); !found {
+		t.Fatal("expected v1 in cache")
+	}
+	putFile(t, c, "v3", "cccc")
+
+	if _, found := cachedFile(t, c, "v2"); found {
+		t.Fatal("expected least recently used v2 to be evicted")
+	}
+	for _, version := range []string{"v1", "v3"} {
+		if _, found := cachedFile(t, c, version); !found {
+			t.Fatalf("expected %s to stay in cache", version)
+		}
+	}
+	if size := c.Size(); size != 8 {
+		t.Fatalf("expected size 8, got %d", size)
+	}
+}
+
+func TestLoadRebuildsIndex(t *testing.T) {
+
+	dir := t.TempDir()
+	c, err := NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putFile(t, c, "v1", "old-content")
+	putFile(t, c, "v2", "new-content")
+	// одинаковое содержимое двух версий хранится одним блобом
+	putFile(t, c, "v3", "new-content")
+
+	// порядок LRU восстанавливается по времени модификации блобов
+	old := time.Now().Add(-time.Hour)
+	oldDigest := c.refs[refKey("tool", "v1", "tool.bin")].Digest
+	if err = os.Chtimes(c.blobPath(oldDigest), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	orphan := c.blobPath(strings.Repeat("ab", 32))
+	_ = os.MkdirAll(filepath.Dir(orphan), 0o755)
+	_ = os.WriteFile(orphan, []byte("orphan"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, refsDir, "broken"+refExt), []byte("{"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, tmpDir, "blob-partial"), []byte("partial"), 0o644)
+
+	if c, err = NewCache(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for version, want := range map[string]string{"v1": "old-content", "v2": "new-content", "v3": "new-content"} {
+		if content, found := cachedFile(t, c, version); !found || content != want {
+			t.Fatalf("%s: expected %q after reload, got %q (found: %v)", version, want, content, found)
+		}
+	}
+	if size := c.Size(); size != int64(len("old-content")+len("new-content")) {
+		t.Fatalf("expected size of two blobs, got %d", size)
+	}
+	if _, err = os.Stat(orphan); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected blob without refs to be removed, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, refsDir, "broken"+refExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected unreadable ref to be removed, got %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, tmpDir)); len(entries) != 0 {
+		t.Fatalf("expected tmp to be cleaned, got %d entries", len(entries))
+	}
+
+	if err = os.Chtimes(c.blobPath(oldDigest), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = NewCache(dir, MaxBytes(int64(len("new-content")))); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := cachedFile(t, c, "v1"); found {
+		t.Fatal("expected the oldest blob to be evicted on load")
+	}
+	if _, found := cachedFile(t, c, "v2"); !found {
+		t.Fatal("expected the newest blob to survive eviction on load")
+	}
+}
+
+func TestPutFileAbortsOnReadError(t *testing.T) {
+
+	dir := t.TempDir()
+	c, err := NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readErr := errors.New("stream closed before EOF")
+	body := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(readErr))
+	if err = c.PutFile(context.Background(), "tool", "v1", "tool.bin", domain.FileInfo{}, body); !errors.Is(err, readErr) {
+		t.Fatalf("expected read error, got %v", err)
+	}
+
+	if _, found := cachedFile(t, c, "v1"); found {
+		t.Fatal("expected incomplete file not to be cached")
+	}
+	if size := c.Size(); size != 0 {
+		t.Fatalf("expected empty cache, got size %d", size)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, tmpDir)); len(entries) != 0 {
+		t.Fatalf("expected temporary file to be removed, got %d entries", len(entries))
+	}
+}
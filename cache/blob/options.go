@@ -0,0 +1,10 @@
+package blob
+
+type CacheOption func(*Cache)
+
+// MaxBytes ограничивает суммарный размер файлов в кеше; при превышении вытесняются давно не запрошенные файлы.
+func MaxBytes(maxBytes int64) (opt CacheOption) {
+	return func(c *Cache) {
+		c.maxBytes = maxBytes
+	}
+}
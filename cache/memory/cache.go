@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"container/list"
 	"context"
 	"sync"
 	"time"
@@ -8,46 +9,106 @@ import (
 	"github.com/seniorGolang/tg-proxy/model/domain"
 )
 
-type cacheEntry struct {
-	project  domain.Project
-	expires  time.Time
-	versions []string
-}
+const defaultJanitorInterval = time.Minute
 
-type aggregateEntry struct {
+type manifestEntry struct {
 	manifest []byte
 	expires  time.Time
 }
 
+type cacheStats struct {
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
+}
+
+// Stats — счётчики кеша для метрик. Hits и Misses учитывают все Get-методы, Evictions — вытеснения по лимитам,
+// Expirations — удаление истёкших записей.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+	Entries     int
+	Bytes       int64
+}
+
+// Cache хранит проекты, версии, манифесты, отметки отсутствия и ответы источников в одном LRU-списке.
+// Без MaxEntries и MaxBytes кеш не ограничен, но истёкшие записи всё равно удаляет фоновый janitor.
 type Cache struct {
-	mu        sync.RWMutex
-	projects  map[string]*cacheEntry
-	versions  map[string]*cacheEntry
-	aggregate *aggregateEntry
+	mu              sync.Mutex
+	order           *list.List
+	items           map[string]*list.Element
+	aliases         map[string]map[string]*list.Element
+	aggregate       *manifestEntry
+	bytes           int64
+	stats           cacheStats
+	staleTTL        time.Duration
+	maxEntries      int
+	maxBytes        int64
+	janitorInterval time.Duration
+	done            chan struct{}
+	closeOnce       sync.Once
 }
 
-func NewCache() (c *Cache) {
-	return &Cache{
-		projects: make(map[string]*cacheEntry),
-		versions: make(map[string]*cacheEntry),
+func NewCache(opts ...CacheOption) (c *Cache) {
+
+	c = &Cache{
+		order:           list.New(),
+		items:           make(map[string]*list.Element),
+		aliases:         make(map[string]map[string]*list.Element),
+		janitorInterval: defaultJanitorInterval,
+		done:            make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	if c.janitorInterval > 0 {
+		go c.janitor(c.janitorInterval)
+	}
+
+	return
 }
 
-func (c *Cache) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, err error) {
+// Close останавливает фоновый janitor. Кеш остаётся рабочим.
+func (c *Cache) Close() (err error) {
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
 
-	entry, exists := c.projects[alias]
-	if !exists {
-		return
+	return
+}
+
+func (c *Cache) Stats() (stats Stats) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Hits:        c.stats.hits,
+		Misses:      c.stats.misses,
+		Evictions:   c.stats.evictions,
+		Expirations: c.stats.expirations,
+		Entries:     c.order.Len(),
+		Bytes:       c.bytes,
 	}
+}
+
+func (c *Cache) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, stale bool, err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if time.Now().After(entry.expires) {
+	entry := c.lookup(projectKey(alias))
+	if entry == nil {
 		return
 	}
 
-	return entry.project, true, nil
+	return entry.project, true, time.Now().After(entry.expires), nil
 }
 
 func (c *Cache) SetProject(ctx context.Context, alias string, project domain.Project, ttl time.Duration) (err error) {
@@ -55,10 +116,14 @@ func (c *Cache) SetProject(ctx context.Context, alias string, project domain.Pro
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.projects[alias] = &cacheEntry{
+	c.store(&cacheEntry{
+		key:     projectKey(alias),
+		alias:   alias,
+		kind:    kindProject,
 		project: project,
 		expires: time.Now().Add(ttl),
-	}
+		size:    projectSize(project),
+	})
 
 	return
 }
@@ -68,29 +133,24 @@ func (c *Cache) DeleteProject(ctx context.Context, alias string) (err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	delete(c.projects, alias)
-	delete(c.versions, alias)
+	c.removeAlias(alias, kindProject, kindVersions, kindManifest)
 
 	return
 }
 
-func (c *Cache) GetVersions(ctx context.Context, alias string) (versions []string, found bool, err error) {
+func (c *Cache) GetVersions(ctx context.Context, alias string) (versions []string, found bool, stale bool, err error) {
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	entry, exists := c.versions[alias]
-	if !exists {
-		return
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if time.Now().After(entry.expires) {
+	entry := c.lookup(versionsKey(alias))
+	if entry == nil {
 		return
 	}
 
+	found, stale = true, time.Now().After(entry.expires)
 	versions = make([]string, len(entry.versions))
 	copy(versions, entry.versions)
-	found = true
 
 	return
 }
@@ -100,30 +160,32 @@ func (c *Cache) SetVersions(ctx context.Context, alias string, versions []string
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.versions[alias] = &cacheEntry{
+	c.store(&cacheEntry{
+		key:      versionsKey(alias),
+		alias:    alias,
+		kind:     kindVersions,
 		versions: versions,
 		expires:  time.Now().Add(ttl),
-	}
+		size:     versionsSize(versions),
+	})
 
 	return
 }
 
 func (c *Cache) GetAggregateManifest(ctx context.Context) (manifest []byte, found bool, err error) {
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	if c.aggregate == nil {
-		return
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if time.Now().After(c.aggregate.expires) {
+	if c.aggregate == nil || time.Now().After(c.aggregate.expires) {
+		c.stats.misses++
 		return
 	}
 
 	manifest = make([]byte, len(c.aggregate.manifest))
 	copy(manifest, c.aggregate.manifest)
 	found = true
+	c.stats.hits++
 
 	return
 }
@@ -135,7 +197,7 @@ func (c *Cache) SetAggregateManifest(ctx context.Context, manifest []byte, ttl t
 
 	data := make([]byte, len(manifest))
 	copy(data, manifest)
-	c.aggregate = &aggregateEntry{
+	c.aggregate = &manifestEntry{
 		manifest: data,
 		expires:  time.Now().Add(ttl),
 	}
@@ -143,14 +205,162 @@ func (c *Cache) SetAggregateManifest(ctx context.Context, manifest []byte, ttl t
 	return
 }
 
+func (c *Cache) DeleteAggregateManifest(ctx context.Context) (err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.aggregate = nil
+
+	return
+}
+
+func (c *Cache) GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, found bool, stale bool, err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.lookup(manifestKey(alias, version, baseURL))
+	if entry == nil {
+		return
+	}
+
+	found, stale = true, time.Now().After(entry.expires)
+	manifest = make([]byte, len(entry.data))
+	copy(manifest, entry.data)
+
+	return
+}
+
+func (c *Cache) SetManifest(ctx context.Context, alias string, version string, baseURL string, manifest []byte, ttl time.Duration) (err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := make([]byte, len(manifest))
+	copy(data, manifest)
+	c.store(&cacheEntry{
+		key:     manifestKey(alias, version, baseURL),
+		alias:   alias,
+		kind:    kindManifest,
+		data:    data,
+		expires: time.Now().Add(ttl),
+		size:    int64(len(data)),
+	})
+
+	return
+}
+
+func (c *Cache) GetNotFound(ctx context.Context, alias string, key string) (found bool, err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found = c.lookup(notFoundKey(alias, key)) != nil
+
+	return
+}
+
+func (c *Cache) SetNotFound(ctx context.Context, alias string, key string, ttl time.Duration) (err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(&cacheEntry{
+		key:     notFoundKey(alias, key),
+		alias:   alias,
+		kind:    kindNotFound,
+		expires: time.Now().Add(ttl),
+	})
+
+	return
+}
+
+func (c *Cache) DeleteNotFound(ctx context.Context, alias string) (err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeAlias(alias, kindNotFound)
+
+	return
+}
+
+func (c *Cache) GetUpstreamResponse(ctx context.Context, key string) (resp domain.UpstreamResponse, found bool, err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.lookup(upstreamKey(key))
+	if entry == nil {
+		return
+	}
+
+	resp = domain.UpstreamResponse{
+		Body:         make([]byte, len(entry.data)),
+		ETag:         entry.etag,
+		LastModified: entry.lastModified,
+	}
+	copy(resp.Body, entry.data)
+
+	return resp, true, nil
+}
+
+func (c *Cache) SetUpstreamResponse(ctx context.Context, key string, resp domain.UpstreamResponse, ttl time.Duration) (err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := make([]byte, len(resp.Body))
+	copy(data, resp.Body)
+	c.store(&cacheEntry{
+		key:          upstreamKey(key),
+		kind:         kindUpstream,
+		data:         data,
+		etag:         resp.ETag,
+		lastModified: resp.LastModified,
+		expires:      time.Now().Add(ttl),
+		size:         int64(len(data) + len(resp.ETag) + len(resp.LastModified)),
+	})
+
+	return
+}
+
 func (c *Cache) Clear(ctx context.Context) (err error) {
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.projects = make(map[string]*cacheEntry)
-	c.versions = make(map[string]*cacheEntry)
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.aliases = make(map[string]map[string]*list.Element)
 	c.aggregate = nil
+	c.bytes = 0
 
 	return
 }
+
+func projectKey(alias string) (key string) {
+
+	return "project\x00" + alias
+}
+
+func versionsKey(alias string) (key string) {
+
+	return "versions\x00" + alias
+}
+
+func manifestKey(alias string, version string, baseURL string) (key string) {
+
+	return "manifest\x00" + alias + "\x00" + version + "\x00" + baseURL
+}
+
+func notFoundKey(alias string, key string) (fullKey string) {
+
+	return "notfound\x00" + alias + "\x00" + key
+}
+
+func upstreamKey(key string) (fullKey string) {
+
+	return "upstream\x00" + key
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+type entryKind uint8
+
+const (
+	kindProject entryKind = iota
+	kindVersions
+	kindManifest
+	kindNotFound
+	kindUpstream
+)
+
+// entryOverhead — грубая оценка накладных расходов на запись (элемент списка, ключи индексов, заголовки срезов).
+const entryOverhead = 128
+
+type cacheEntry struct {
+	key          string
+	alias        string
+	kind         entryKind
+	project      domain.Project
+	versions     []string
+	data         []byte
+	etag         string
+	lastModified string
+	expires      time.Time
+	size         int64
+}
+
+// lookup возвращает живую запись и поднимает её в начало LRU-списка; истёкшая запись удаляется. Вызывается под c.mu.
+func (c *Cache) lookup(key string) (entry *cacheEntry) {
+
+	elem, exists := c.items[key]
+	if !exists {
+		c.stats.misses++
+		return
+	}
+
+	if entry = elem.Value.(*cacheEntry); c.expired(entry, time.Now()) {
+		c.remove(elem)
+		c.stats.expirations++
+		c.stats.misses++
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	c.stats.hits++
+	return
+}
+
+// store кладёт запись в кеш и вытесняет самые старые записи сверх лимитов. Вызывается под c.mu.
+func (c *Cache) store(entry *cacheEntry) {
+
+	entry.size += int64(len(entry.key)) + entryOverhead
+	if elem, exists := c.items[entry.key]; exists {
+		c.remove(elem)
+	}
+
+	if c.maxBytes > 0 && entry.size > c.maxBytes {
+		return
+	}
+
+	elem := c.order.PushFront(entry)
+	c.items[entry.key] = elem
+	keys, exists := c.aliases[entry.alias]
+	if !exists {
+		keys = make(map[string]*list.Element)
+		c.aliases[entry.alias] = keys
+	}
+	keys[entry.key] = elem
+	c.bytes += entry.size
+
+	for c.overLimit() {
+		c.remove(c.order.Back())
+		c.stats.evictions++
+	}
+}
+
+// removeAlias удаляет записи проекта указанных видов. Вызывается под c.mu.
+func (c *Cache) removeAlias(alias string, kinds ...entryKind) {
+
+	for _, elem := range c.aliases[alias] {
+		entry := elem.Value.(*cacheEntry)
+		for _, kind := range kinds {
+			if entry.kind == kind {
+				c.remove(elem)
+				break
+			}
+		}
+	}
+}
+
+func (c *Cache) remove(elem *list.Element) {
+
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.items, entry.key)
+	if keys := c.aliases[entry.alias]; keys != nil {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.aliases, entry.alias)
+		}
+	}
+	c.bytes -= entry.size
+}
+
+func (c *Cache) overLimit() (over bool) {
+
+	if c.order.Len() == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// expired — запись больше нельзя отдавать даже как устаревшую.
+func (c *Cache) expired(entry *cacheEntry, now time.Time) (expired bool) {
+
+	if entry.kind == kindNotFound {
+		return now.After(entry.expires)
+	}
+	return now.After(entry.expires.Add(c.staleTTL))
+}
+
+// janitor периодически удаляет истёкшие записи, чтобы они не занимали память до вытеснения.
+func (c *Cache) janitor(interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.removeExpired()
+		}
+	}
+}
+
+func (c *Cache) removeExpired() {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.order.Back(); elem != nil; {
+		prev := elem.Prev()
+		if c.expired(elem.Value.(*cacheEntry), now) {
+			c.remove(elem)
+			c.stats.expirations++
+		}
+		elem = prev
+	}
+	if c.aggregate != nil && now.After(c.aggregate.expires) {
+		c.aggregate = nil
+		c.stats.expirations++
+	}
+}
+
+func projectSize(project domain.Project) (size int64) {
+
+	size = int64(len(project.Alias) + len(project.RepoURL) + len(project.EncryptedToken) + len(project.Token) +
+		len(project.Description) + len(project.SourceName))
+	for key, value := range project.Settings {
+		size += int64(len(key) + len(value))
+	}
+	return
+}
+
+func versionsSize(versions []string) (size int64) {
+
+	for _, version := range versions {
+		size += int64(len(version)) + 16
+	}
+	return
+}
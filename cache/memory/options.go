@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"time"
+)
+
+type CacheOption func(*Cache)
+
+// StaleTTL задаёт grace-окно: истёкшие проекты, версии и манифесты хранятся ещё столько времени
+// и отдаются как устаревшие, пока движок обновляет их в фоне или источник недоступен.
+func StaleTTL(ttl time.Duration) (opt CacheOption) {
+	return func(c *Cache) {
+		c.staleTTL = ttl
+	}
+}
+
+// MaxEntries ограничивает число записей; при превышении вытесняются давно не читавшиеся. 0 — без ограничения.
+func MaxEntries(n int) (opt CacheOption) {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// MaxBytes ограничивает примерный объём данных в кеше; запись крупнее лимита не сохраняется. 0 — без ограничения.
+func MaxBytes(n int64) (opt CacheOption) {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// JanitorInterval задаёт период фоновой очистки истёкших записей (по умолчанию минута). 0 отключает janitor.
+func JanitorInterval(interval time.Duration) (opt CacheOption) {
+	return func(c *Cache) {
+		c.janitorInterval = interval
+	}
+}
@@ -14,41 +14,56 @@ import (
 )
 
 const (
-	projectKeyPrefix     = "project:"
-	versionsKeyPrefix    = "versions:"
-	aggregateManifestKey = "aggregate_manifest"
+	projectKeyPrefix       = "project:"
+	versionsKeyPrefix      = "versions:"
+	manifestKeyPrefix      = "manifest:"
+	manifestIndexKeyPrefix = "manifests:"
+	freshKeyPrefix         = "fresh:"
+	notFoundKeyPrefix      = "notfound:"
+	upstreamKeyPrefix      = "upstream:"
+	aggregateManifestKey   = "aggregate_manifest"
 )
 
 type Cache struct {
-	client *redis.Client
+	client   *redis.Client
+	staleTTL time.Duration
 }
 
-func NewCache(client *redis.Client) (c *Cache) {
-	return &Cache{
+func NewCache(client *redis.Client, opts ...CacheOption) (c *Cache) {
+
+	c = &Cache{
 		client: client,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return
 }
 
-func (c *Cache) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, err error) {
+// Ping проверяет соединение с Redis; используется проверкой готовности прокси.
+func (c *Cache) Ping(ctx context.Context) (err error) {
+
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *Cache) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, stale bool, err error) {
 
 	key := projectKeyPrefix + alias
 	var data string
-	if data, err = c.client.Get(ctx, key).Result(); err != nil {
-		if errors.Is(err, redis.Nil) {
-			err = nil
-			return
-		}
+	if data, found, stale, err = c.get(ctx, key); err != nil || !found {
 		return
 	}
 
 	var doc internal.Project
 	if err = json.Unmarshal([]byte(data), &doc); err != nil {
+		found = false
 		err = fmt.Errorf("failed to unmarshal project: %w", err)
 		return
 	}
 
 	project = doc.ToDomain()
-	found = true
 	return
 }
 
@@ -62,7 +77,9 @@ func (c *Cache) SetProject(ctx context.Context, alias string, project domain.Pro
 		return
 	}
 
-	if err = c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+	pipe := c.client.TxPipeline()
+	c.set(ctx, pipe, key, data, ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
 		err = fmt.Errorf("failed to set project in cache: %w", err)
 		return
 	}
@@ -74,10 +91,20 @@ func (c *Cache) DeleteProject(ctx context.Context, alias string) (err error) {
 
 	projectKey := projectKeyPrefix + alias
 	versionsKey := versionsKeyPrefix + alias
+	indexKey := manifestIndexKeyPrefix + alias
+
+	var manifestKeys []string
+	if manifestKeys, err = c.client.SMembers(ctx, indexKey).Result(); err != nil {
+		err = fmt.Errorf("failed to get manifest keys: %w", err)
+		return
+	}
 
+	keys := append([]string{projectKey, versionsKey}, manifestKeys...)
 	pipe := c.client.Pipeline()
-	pipe.Del(ctx, projectKey)
-	pipe.Del(ctx, versionsKey)
+	for _, key := range keys {
+		pipe.Del(ctx, key, freshKeyPrefix+key)
+	}
+	pipe.Del(ctx, indexKey)
 
 	if _, err = pipe.Exec(ctx); err != nil {
 		err = fmt.Errorf("failed to delete project from cache: %w", err)
@@ -87,24 +114,20 @@ func (c *Cache) DeleteProject(ctx context.Context, alias string) (err error) {
 	return
 }
 
-func (c *Cache) GetVersions(ctx context.Context, alias string) (versions []string, found bool, err error) {
+func (c *Cache) GetVersions(ctx context.Context, alias string) (versions []string, found bool, stale bool, err error) {
 
 	key := versionsKeyPrefix + alias
 	var data string
-	if data, err = c.client.Get(ctx, key).Result(); err != nil {
-		if errors.Is(err, redis.Nil) {
-			err = nil
-			return
-		}
+	if data, found, stale, err = c.get(ctx, key); err != nil || !found {
 		return
 	}
 
 	if err = json.Unmarshal([]byte(data), &versions); err != nil {
+		found = false
 		err = fmt.Errorf("failed to unmarshal versions: %w", err)
 		return
 	}
 
-	found = true
 	return
 }
 
@@ -117,7 +140,9 @@ func (c *Cache) SetVersions(ctx context.Context, alias string, versions []string
 		return
 	}
 
-	if err = c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+	pipe := c.client.TxPipeline()
+	c.set(ctx, pipe, key, data, ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
 		err = fmt.Errorf("failed to set versions in cache: %w", err)
 		return
 	}
@@ -152,6 +177,130 @@ func (c *Cache) SetAggregateManifest(ctx context.Context, manifest []byte, ttl t
 	return
 }
 
+func (c *Cache) DeleteAggregateManifest(ctx context.Context) (err error) {
+
+	if err = c.client.Del(ctx, aggregateManifestKey).Err(); err != nil {
+		err = fmt.Errorf("failed to delete aggregate manifest from cache: %w", err)
+		return
+	}
+
+	return
+}
+
+func (c *Cache) GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, found bool, stale bool, err error) {
+
+	var data string
+	if data, found, stale, err = c.get(ctx, manifestKey(alias, version, baseURL)); err != nil {
+		err = fmt.Errorf("failed to get manifest from cache: %w", err)
+		return
+	}
+
+	manifest = []byte(data)
+	return
+}
+
+// SetManifest сохраняет манифест и добавляет его ключ в индекс проекта, чтобы DeleteProject удалял манифесты без SCAN.
+func (c *Cache) SetManifest(ctx context.Context, alias string, version string, baseURL string, manifest []byte, ttl time.Duration) (err error) {
+
+	key := manifestKey(alias, version, baseURL)
+	indexKey := manifestIndexKeyPrefix + alias
+
+	pipe := c.client.TxPipeline()
+	c.set(ctx, pipe, key, manifest, ttl)
+	pipe.SAdd(ctx, indexKey, key)
+	if ttl > 0 {
+		pipe.Expire(ctx, indexKey, ttl+c.staleTTL)
+	}
+
+	if _, err = pipe.Exec(ctx); err != nil {
+		err = fmt.Errorf("failed to set manifest in cache: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetNotFound читает отметку из хеша notfound:<alias>: поле — ключ отметки, значение — время истечения в Unix-секундах.
+// Один хеш на проект позволяет снять все отметки одним DEL.
+func (c *Cache) GetNotFound(ctx context.Context, alias string, key string) (found bool, err error) {
+
+	var expires int64
+	if expires, err = c.client.HGet(ctx, notFoundKeyPrefix+alias, key).Int64(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("failed to get not-found mark from cache: %w", err)
+		return
+	}
+
+	return time.Now().Unix() < expires, nil
+}
+
+func (c *Cache) SetNotFound(ctx context.Context, alias string, key string, ttl time.Duration) (err error) {
+
+	hashKey := notFoundKeyPrefix + alias
+
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, hashKey, key, time.Now().Add(ttl).Unix())
+	pipe.Expire(ctx, hashKey, ttl)
+
+	if _, err = pipe.Exec(ctx); err != nil {
+		err = fmt.Errorf("failed to set not-found mark in cache: %w", err)
+		return
+	}
+
+	return
+}
+
+func (c *Cache) DeleteNotFound(ctx context.Context, alias string) (err error) {
+
+	if err = c.client.Del(ctx, notFoundKeyPrefix+alias).Err(); err != nil {
+		err = fmt.Errorf("failed to delete not-found marks from cache: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetUpstreamResponse не учитывает grace-окно: сохранённый ответ всё равно перепроверяется у источника.
+func (c *Cache) GetUpstreamResponse(ctx context.Context, key string) (resp domain.UpstreamResponse, found bool, err error) {
+
+	var data []byte
+	if data, err = c.client.Get(ctx, upstreamKeyPrefix+key).Bytes(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("failed to get upstream response from cache: %w", err)
+		return
+	}
+
+	var doc internal.UpstreamResponse
+	if err = json.Unmarshal(data, &doc); err != nil {
+		err = fmt.Errorf("failed to unmarshal upstream response: %w", err)
+		return
+	}
+
+	return doc.ToDomain(), true, nil
+}
+
+func (c *Cache) SetUpstreamResponse(ctx context.Context, key string, resp domain.UpstreamResponse, ttl time.Duration) (err error) {
+
+	var data []byte
+	if data, err = json.Marshal(internal.UpstreamFromDomain(resp)); err != nil {
+		err = fmt.Errorf("failed to marshal upstream response: %w", err)
+		return
+	}
+
+	if err = c.client.Set(ctx, upstreamKeyPrefix+key, data, ttl).Err(); err != nil {
+		err = fmt.Errorf("failed to set upstream response in cache: %w", err)
+		return
+	}
+
+	return
+}
+
 func (c *Cache) Clear(ctx context.Context) (err error) {
 
 	var keys []string
@@ -176,6 +325,17 @@ func (c *Cache) Clear(ctx context.Context) (err error) {
 		return
 	}
 
+	for _, pattern := range []string{manifestKeyPrefix + "*", manifestIndexKeyPrefix + "*", freshKeyPrefix + "*", notFoundKeyPrefix + "*", upstreamKeyPrefix + "*"} {
+		iter = c.client.Scan(ctx, 0, pattern, 0).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err = iter.Err(); err != nil {
+			err = fmt.Errorf("failed to scan keys: %w", err)
+			return
+		}
+	}
+
 	keys = append(keys, aggregateManifestKey)
 
 	if len(keys) > 0 {
@@ -187,3 +347,107 @@ func (c *Cache) Clear(ctx context.Context) (err error) {
 
 	return
 }
+
+// ProjectTTL, VersionsTTL, ManifestTTL, AggregateManifestTTL и UpstreamResponseTTL сообщают, сколько ещё запись
+// останется свежей: по ним двухуровневый кеш выбирает срок копии в первом уровне. found = false — записи нет,
+// нулевой ttl — запись без срока.
+func (c *Cache) ProjectTTL(ctx context.Context, alias string) (ttl time.Duration, found bool, err error) {
+
+	return c.freshTTL(ctx, projectKeyPrefix+alias, true)
+}
+
+func (c *Cache) VersionsTTL(ctx context.Context, alias string) (ttl time.Duration, found bool, err error) {
+
+	return c.freshTTL(ctx, versionsKeyPrefix+alias, true)
+}
+
+func (c *Cache) ManifestTTL(ctx context.Context, alias string, version string, baseURL string) (ttl time.Duration, found bool, err error) {
+
+	return c.freshTTL(ctx, manifestKey(alias, version, baseURL), true)
+}
+
+func (c *Cache) AggregateManifestTTL(ctx context.Context) (ttl time.Duration, found bool, err error) {
+
+	return c.freshTTL(ctx, aggregateManifestKey, false)
+}
+
+func (c *Cache) UpstreamResponseTTL(ctx context.Context, key string) (ttl time.Duration, found bool, err error) {
+
+	return c.freshTTL(ctx, upstreamKeyPrefix+key, false)
+}
+
+// freshTTL — остаток срока записи; для записей с grace-окном (graced) считается по метке свежести.
+func (c *Cache) freshTTL(ctx context.Context, key string, graced bool) (ttl time.Duration, found bool, err error) {
+
+	if graced && c.staleTTL > 0 {
+		key = freshKeyPrefix + key
+	}
+
+	if ttl, err = c.client.PTTL(ctx, key).Result(); err != nil {
+		err = fmt.Errorf("failed to get cache entry TTL: %w", err)
+		return
+	}
+
+	// PTTL возвращает -2 для отсутствующего ключа и -1 для ключа без срока
+	switch ttl {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return 0, true, nil
+	}
+	return ttl, true, nil
+}
+
+// get читает запись. При grace-окне рядом с ней хранится метка свежести fresh:<key> с исходным TTL:
+// запись без метки истекла, но ещё может быть отдана как устаревшая.
+func (c *Cache) get(ctx context.Context, key string) (data string, found bool, stale bool, err error) {
+
+	if c.staleTTL <= 0 {
+		if data, err = c.client.Get(ctx, key).Result(); err != nil {
+			if errors.Is(err, redis.Nil) {
+				err = nil
+			}
+			return
+		}
+		found = true
+		return
+	}
+
+	pipe := c.client.Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	freshCmd := pipe.Exists(ctx, freshKeyPrefix+key)
+	if _, err = pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return
+	}
+	if data, err = getCmd.Result(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = nil
+		}
+		return
+	}
+
+	found = true
+	stale = freshCmd.Val() == 0
+	return
+}
+
+// set добавляет в pipe запись с TTL, продлённым на grace-окно, и метку свежести с исходным TTL.
+func (c *Cache) set(ctx context.Context, pipe redis.Pipeliner, key string, value any, ttl time.Duration) {
+
+	if c.staleTTL <= 0 {
+		pipe.Set(ctx, key, value, ttl)
+		return
+	}
+
+	storeTTL := ttl
+	if ttl > 0 {
+		storeTTL = ttl + c.staleTTL
+	}
+	pipe.Set(ctx, key, value, storeTTL)
+	pipe.Set(ctx, freshKeyPrefix+key, 1, ttl)
+}
+
+func manifestKey(alias string, version string, baseURL string) (key string) {
+
+	return manifestKeyPrefix + alias + ":" + version + ":" + baseURL
+}
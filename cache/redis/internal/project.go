@@ -9,15 +9,16 @@ import (
 )
 
 type Project struct {
-	ID             uuid.UUID `json:"id"`
-	Alias          string    `json:"alias"`
-	RepoURL        string    `json:"repo_url"`
-	EncryptedToken string    `json:"encrypted_token"`
-	Token          string    `json:"token"`
-	Description    string    `json:"description"`
-	SourceName     string    `json:"source_name"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uuid.UUID         `json:"id"`
+	Alias          string            `json:"alias"`
+	RepoURL        string            `json:"repo_url"`
+	EncryptedToken string            `json:"encrypted_token"`
+	Token          string            `json:"token"`
+	Description    string            `json:"description"`
+	SourceName     string            `json:"source_name"`
+	Settings       map[string]string `json:"settings,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
 }
 
 func (d Project) ToDomain() (project domain.Project) {
@@ -30,6 +31,7 @@ func (d Project) ToDomain() (project domain.Project) {
 		Token:          d.Token,
 		Description:    d.Description,
 		SourceName:     d.SourceName,
+		Settings:       d.Settings,
 		CreatedAt:      d.CreatedAt,
 		UpdatedAt:      d.UpdatedAt,
 	}
@@ -45,6 +47,7 @@ func FromDomain(project domain.Project) (doc Project) {
 		Token:          project.Token,
 		Description:    project.Description,
 		SourceName:     project.SourceName,
+		Settings:       project.Settings,
 		CreatedAt:      project.CreatedAt,
 		UpdatedAt:      project.UpdatedAt,
 	}
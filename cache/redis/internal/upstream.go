@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+type UpstreamResponse struct {
+	Body         []byte `json:"body"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func (d UpstreamResponse) ToDomain() (resp domain.UpstreamResponse) {
+
+	return domain.UpstreamResponse{
+		Body:         d.Body,
+		ETag:         d.ETag,
+		LastModified: d.LastModified,
+	}
+}
+
+func UpstreamFromDomain(resp domain.UpstreamResponse) (doc UpstreamResponse) {
+
+	return UpstreamResponse{
+		Body:         resp.Body,
+		ETag:         resp.ETag,
+		LastModified: resp.LastModified,
+	}
+}
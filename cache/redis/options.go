@@ -0,0 +1,15 @@
+package redis
+
+import (
+	"time"
+)
+
+type CacheOption func(*Cache)
+
+// StaleTTL задаёт grace-окно: истёкшие проекты, версии и манифесты хранятся ещё столько времени
+// и отдаются как устаревшие, пока движок обновляет их в фоне или источник недоступен.
+func StaleTTL(ttl time.Duration) (opt CacheOption) {
+	return func(c *Cache) {
+		c.staleTTL = ttl
+	}
+}
@@ -0,0 +1,287 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+const (
+	defaultL1MaxTTL = time.Minute
+	// l1TTLDivisor — во сколько раз TTL первого уровня короче TTL второго.
+	l1TTLDivisor = 10
+)
+
+type tier interface {
+	GetProject(ctx context.Context, alias string) (project domain.Project, found bool, stale bool, err error)
+	SetProject(ctx context.Context, alias string, project domain.Project, ttl time.Duration) (err error)
+	DeleteProject(ctx context.Context, alias string) (err error)
+	GetVersions(ctx context.Context, alias string) (versions []string, found bool, stale bool, err error)
+	SetVersions(ctx context.Context, alias string, versions []string, ttl time.Duration) (err error)
+	GetAggregateManifest(ctx context.Context) (manifest []byte, found bool, err error)
+	SetAggregateManifest(ctx context.Context, manifest []byte, ttl time.Duration) (err error)
+	DeleteAggregateManifest(ctx context.Context) (err error)
+	GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, found bool, stale bool, err error)
+	SetManifest(ctx context.Context, alias string, version string, baseURL string, manifest []byte, ttl time.Duration) (err error)
+	GetNotFound(ctx context.Context, alias string, key string) (found bool, err error)
+	SetNotFound(ctx context.Context, alias string, key string, ttl time.Duration) (err error)
+	DeleteNotFound(ctx context.Context, alias string) (err error)
+	GetUpstreamResponse(ctx context.Context, key string) (resp domain.UpstreamResponse, found bool, err error)
+	SetUpstreamResponse(ctx context.Context, key string, resp domain.UpstreamResponse, ttl time.Duration) (err error)
+	Clear(ctx context.Context) (err error)
+}
+
+// expiryTier — необязательное расширение второго уровня: остаток срока записи. Без него первый уровень
+// прогревается на L1MaxTTL.
+type expiryTier interface {
+	ProjectTTL(ctx context.Context, alias string) (ttl time.Duration, found bool, err error)
+	VersionsTTL(ctx context.Context, alias string) (ttl time.Duration, found bool, err error)
+	ManifestTTL(ctx context.Context, alias string, version string, baseURL string) (ttl time.Duration, found bool, err error)
+	AggregateManifestTTL(ctx context.Context) (ttl time.Duration, found bool, err error)
+	UpstreamResponseTTL(ctx context.Context, key string) (ttl time.Duration, found bool, err error)
+}
+
+type pinger interface {
+	Ping(ctx context.Context) (err error)
+}
+
+// Cache — двухуровневый кеш: быстрый локальный первый уровень (cache/memory) перед общим вторым (cache/redis).
+// Чтение идёт сквозь уровни, запись и удаление — в оба. Записи первого уровня живут в l1TTLDivisor раз меньше,
+// но не дольше L1MaxTTL: изменения других реплик во втором уровне становятся видны не позже этого срока.
+type Cache struct {
+	l1       tier
+	l2       tier
+	l1MaxTTL time.Duration
+}
+
+func NewCache(l1 tier, l2 tier, opts ...CacheOption) (c *Cache) {
+
+	c = &Cache{
+		l1:       l1,
+		l2:       l2,
+		l1MaxTTL: defaultL1MaxTTL,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return
+}
+
+// GetProject отдаёт свежую запись первого уровня; иначе читает второй и при свежем попадании прогревает первый
+// на долю оставшегося во втором уровне срока, чтобы копия не пережила оригинал.
+// Если второй уровень недоступен или пуст, отдаётся устаревшая запись первого уровня, если она есть.
+func (c *Cache) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, stale bool, err error) {
+
+	var l1Project domain.Project
+	var l1Found, l1Stale bool
+	if l1Project, l1Found, l1Stale, _ = c.l1.GetProject(ctx, alias); l1Found && !l1Stale {
+		return l1Project, true, false, nil
+	}
+
+	if project, found, stale, err = c.l2.GetProject(ctx, alias); err != nil || !found {
+		if l1Found {
+			return l1Project, true, true, nil
+		}
+		return
+	}
+
+	if !stale {
+		if ttl, ok := c.warmTTL(func(l2 expiryTier) (time.Duration, bool, error) { return l2.ProjectTTL(ctx, alias) }); ok {
+			_ = c.l1.SetProject(ctx, alias, project, ttl)
+		}
+	}
+
+	return
+}
+
+func (c *Cache) SetProject(ctx context.Context, alias string, project domain.Project, ttl time.Duration) (err error) {
+
+	_ = c.l1.SetProject(ctx, alias, project, c.l1TTL(ttl))
+	return c.l2.SetProject(ctx, alias, project, ttl)
+}
+
+func (c *Cache) DeleteProject(ctx context.Context, alias string) (err error) {
+
+	return errors.Join(c.l1.DeleteProject(ctx, alias), c.l2.DeleteProject(ctx, alias))
+}
+
+func (c *Cache) GetVersions(ctx context.Context, alias string) (versions []string, found bool, stale bool, err error) {
+
+	var l1Versions []string
+	var l1Found, l1Stale bool
+	if l1Versions, l1Found, l1Stale, _ = c.l1.GetVersions(ctx, alias); l1Found && !l1Stale {
+		return l1Versions, true, false, nil
+	}
+
+	if versions, found, stale, err = c.l2.GetVersions(ctx, alias); err != nil || !found {
+		if l1Found {
+			return l1Versions, true, true, nil
+		}
+		return
+	}
+
+	if !stale {
+		if ttl, ok := c.warmTTL(func(l2 expiryTier) (time.Duration, bool, error) { return l2.VersionsTTL(ctx, alias) }); ok {
+			_ = c.l1.SetVersions(ctx, alias, versions, ttl)
+		}
+	}
+
+	return
+}
+
+func (c *Cache) SetVersions(ctx context.Context, alias string, versions []string, ttl time.Duration) (err error) {
+
+	_ = c.l1.SetVersions(ctx, alias, versions, c.l1TTL(ttl))
+	return c.l2.SetVersions(ctx, alias, versions, ttl)
+}
+
+func (c *Cache) GetAggregateManifest(ctx context.Context) (manifest []byte, found bool, err error) {
+
+	if manifest, found, _ = c.l1.GetAggregateManifest(ctx); found {
+		return
+	}
+
+	if manifest, found, err = c.l2.GetAggregateManifest(ctx); err != nil || !found {
+		return
+	}
+
+	if ttl, ok := c.warmTTL(func(l2 expiryTier) (time.Duration, bool, error) { return l2.AggregateManifestTTL(ctx) }); ok {
+		_ = c.l1.SetAggregateManifest(ctx, manifest, ttl)
+	}
+
+	return
+}
+
+func (c *Cache) SetAggregateManifest(ctx context.Context, manifest []byte, ttl time.Duration) (err error) {
+
+	_ = c.l1.SetAggregateManifest(ctx, manifest, c.l1TTL(ttl))
+	return c.l2.SetAggregateManifest(ctx, manifest, ttl)
+}
+
+func (c *Cache) DeleteAggregateManifest(ctx context.Context) (err error) {
+
+	return errors.Join(c.l1.DeleteAggregateManifest(ctx), c.l2.DeleteAggregateManifest(ctx))
+}
+
+func (c *Cache) GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, found bool, stale bool, err error) {
+
+	var l1Manifest []byte
+	var l1Found, l1Stale bool
+	if l1Manifest, l1Found, l1Stale, _ = c.l1.GetManifest(ctx, alias, version, baseURL); l1Found && !l1Stale {
+		return l1Manifest, true, false, nil
+	}
+
+	if manifest, found, stale, err = c.l2.GetManifest(ctx, alias, version, baseURL); err != nil || !found {
+		if l1Found {
+			return l1Manifest, true, true, nil
+		}
+		return
+	}
+
+	if !stale {
+		if ttl, ok := c.warmTTL(func(l2 expiryTier) (time.Duration, bool, error) { return l2.ManifestTTL(ctx, alias, version, baseURL) }); ok {
+			_ = c.l1.SetManifest(ctx, alias, version, baseURL, manifest, ttl)
+		}
+	}
+
+	return
+}
+
+func (c *Cache) SetManifest(ctx context.Context, alias string, version string, baseURL string, manifest []byte, ttl time.Duration) (err error) {
+
+	_ = c.l1.SetManifest(ctx, alias, version, baseURL, manifest, c.l1TTL(ttl))
+	return c.l2.SetManifest(ctx, alias, version, baseURL, manifest, ttl)
+}
+
+// GetNotFound не прогревает первый уровень: остаток срока отметки во втором уровне неизвестен,
+// а отметка, пережившая публикацию версии, прятала бы её дольше, чем задумано.
+func (c *Cache) GetNotFound(ctx context.Context, alias string, key string) (found bool, err error) {
+
+	if found, _ = c.l1.GetNotFound(ctx, alias, key); found {
+		return
+	}
+
+	return c.l2.GetNotFound(ctx, alias, key)
+}
+
+func (c *Cache) SetNotFound(ctx context.Context, alias string, key string, ttl time.Duration) (err error) {
+
+	_ = c.l1.SetNotFound(ctx, alias, key, c.l1TTL(ttl))
+	return c.l2.SetNotFound(ctx, alias, key, ttl)
+}
+
+func (c *Cache) DeleteNotFound(ctx context.Context, alias string) (err error) {
+
+	return errors.Join(c.l1.DeleteNotFound(ctx, alias), c.l2.DeleteNotFound(ctx, alias))
+}
+
+func (c *Cache) GetUpstreamResponse(ctx context.Context, key string) (resp domain.UpstreamResponse, found bool, err error) {
+
+	if resp, found, _ = c.l1.GetUpstreamResponse(ctx, key); found {
+		return
+	}
+
+	if resp, found, err = c.l2.GetUpstreamResponse(ctx, key); err != nil || !found {
+		return
+	}
+
+	if ttl, ok := c.warmTTL(func(l2 expiryTier) (time.Duration, bool, error) { return l2.UpstreamResponseTTL(ctx, key) }); ok {
+		_ = c.l1.SetUpstreamResponse(ctx, key, resp, ttl)
+	}
+
+	return
+}
+
+func (c *Cache) SetUpstreamResponse(ctx context.Context, key string, resp domain.UpstreamResponse, ttl time.Duration) (err error) {
+
+	_ = c.l1.SetUpstreamResponse(ctx, key, resp, c.l1TTL(ttl))
+	return c.l2.SetUpstreamResponse(ctx, key, resp, ttl)
+}
+
+func (c *Cache) Clear(ctx context.Context) (err error) {
+
+	return errors.Join(c.l1.Clear(ctx), c.l2.Clear(ctx))
+}
+
+// Ping проверяет второй уровень, если он это поддерживает: первый уровень локальный и всегда доступен.
+func (c *Cache) Ping(ctx context.Context) (err error) {
+
+	if p, ok := c.l2.(pinger); ok {
+		return p.Ping(ctx)
+	}
+	return
+}
+
+func (c *Cache) l1TTL(ttl time.Duration) (l1TTL time.Duration) {
+
+	if l1TTL = ttl / l1TTLDivisor; l1TTL > c.l1MaxTTL {
+		l1TTL = c.l1MaxTTL
+	}
+	return
+}
+
+// warmTTL — срок копии в первом уровне после чтения из второго: l1TTL от оставшегося там срока записи.
+// ok = false — остаток неизвестен (запись успела истечь или второй уровень не ответил), первый уровень не прогревается.
+func (c *Cache) warmTTL(remaining func(l2 expiryTier) (ttl time.Duration, found bool, err error)) (ttl time.Duration, ok bool) {
+
+	l2, isExpiry := c.l2.(expiryTier)
+	if !isExpiry {
+		return c.l1MaxTTL, true
+	}
+
+	left, found, err := remaining(l2)
+	if err != nil || !found {
+		return
+	}
+	if left == 0 {
+		// запись без срока
+		return c.l1MaxTTL, true
+	}
+
+	ttl = c.l1TTL(left)
+	return ttl, ttl > 0
+}
@@ -0,0 +1,15 @@
+package tiered
+
+import (
+	"time"
+)
+
+type CacheOption func(*Cache)
+
+// L1MaxTTL ограничивает срок жизни записей в первом уровне (по умолчанию минута).
+// Меньше значение — быстрее реплика замечает изменения, сделанные другими репликами во втором уровне.
+func L1MaxTTL(ttl time.Duration) (opt CacheOption) {
+	return func(c *Cache) {
+		c.l1MaxTTL = ttl
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+// invalidationBus рассылает сбросы кеша между репликами. Доставка не гарантируется:
+// пропущенное событие ограничено сроком жизни записей в кеше.
+type invalidationBus interface {
+	Publish(ctx context.Context, event domain.Invalidation) (err error)
+	Subscribe(handler func(ctx context.Context, event domain.Invalidation)) (err error)
+}
+
+// InvalidationBus включает рассылку сбросов кеша при изменении проектов на остальные реплики.
+func InvalidationBus(bus invalidationBus) (opt EngineOption) {
+	return func(e *engine) {
+		e.bus = bus
+	}
+}
+
+// invalidate применяет события к локальным кешам и рассылает их остальным репликам.
+func (e *engine) invalidate(ctx context.Context, events ...domain.Invalidation) {
+
+	for _, event := range events {
+		e.applyInvalidation(ctx, event)
+		if e.bus == nil {
+			continue
+		}
+		event.Origin = e.instanceID
+		if err := e.bus.Publish(ctx, event); err != nil {
+			slog.WarnContext(ctx, "Failed to publish cache invalidation",
+				slog.String(helpers.LogKeyAction, helpers.ActionInvalidateCache),
+				slog.String(helpers.LogKeyInvalidation, string(event.Kind)),
+				slog.String(helpers.LogKeyAlias, event.Alias),
+				slog.Any(helpers.LogKeyError, err),
+			)
+		}
+	}
+}
+
+// handleInvalidation применяет событие, пришедшее от другой реплики.
+func (e *engine) handleInvalidation(ctx context.Context, event domain.Invalidation) {
+
+	if event.Origin == e.instanceID {
+		return
+	}
+
+	slog.DebugContext(ctx, "Cache invalidation received",
+		slog.String(helpers.LogKeyAction, helpers.ActionInvalidateCache),
+		slog.String(helpers.LogKeyInvalidation, string(event.Kind)),
+		slog.String(helpers.LogKeyAlias, event.Alias),
+	)
+	e.applyInvalidation(ctx, event)
+}
+
+func (e *engine) applyInvalidation(ctx context.Context, event domain.Invalidation) {
+
+	switch event.Kind {
+	case domain.InvalidateProject:
+		// версии и манифесты загружались с прежними настройками проекта: их кеш удаляет DeleteProject резолвера,
+		// а идущие загрузки забываются, чтобы новые запросы не присоединились к ним
+		e.aliasFlights.forget(&e.flight, event.Alias)
+		_ = e.resolver.InvalidateCache(ctx, event.Alias)
+	case domain.InvalidateFiles:
+		if e.fileCache != nil {
+			_ = e.fileCache.DeleteFiles(ctx, event.Alias)
+		}
+	case domain.InvalidateAggregate:
+		if e.cache != nil {
+			_ = e.cache.DeleteAggregateManifest(ctx)
+		}
+	}
+}
+
+func projectInvalidations(alias string, withFiles bool) (events []domain.Invalidation) {
+
+	events = []domain.Invalidation{
+		{Kind: domain.InvalidateProject, Alias: alias},
+		{Kind: domain.InvalidateAggregate},
+	}
+	if withFiles {
+		events = append(events, domain.Invalidation{Kind: domain.InvalidateFiles, Alias: alias})
+	}
+	return
+}
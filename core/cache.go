@@ -2,18 +2,43 @@ package core
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/seniorGolang/tg-proxy/model/domain"
 )
 
+// cache хранит данные с TTL. Реализации могут держать истёкшие записи ещё какое-то время (grace-окно):
+// тогда Get возвращает их со stale = true, и движок отдаёт их, обновляя запись в фоне.
 type cache interface {
-	GetProject(ctx context.Context, alias string) (project domain.Project, found bool, err error)
+	GetProject(ctx context.Context, alias string) (project domain.Project, found bool, stale bool, err error)
 	SetProject(ctx context.Context, alias string, project domain.Project, ttl time.Duration) (err error)
+	// DeleteProject удаляет проект вместе с его версиями и манифестами.
 	DeleteProject(ctx context.Context, alias string) (err error)
-	GetVersions(ctx context.Context, alias string) (versions []string, found bool, err error)
+	GetVersions(ctx context.Context, alias string) (versions []string, found bool, stale bool, err error)
 	SetVersions(ctx context.Context, alias string, versions []string, ttl time.Duration) (err error)
 	GetAggregateManifest(ctx context.Context) (manifest []byte, found bool, err error)
 	SetAggregateManifest(ctx context.Context, manifest []byte, ttl time.Duration) (err error)
+	DeleteAggregateManifest(ctx context.Context) (err error)
+	// GetManifest и SetManifest хранят манифест версии проекта после замены URL.
+	GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, found bool, stale bool, err error)
+	SetManifest(ctx context.Context, alias string, version string, baseURL string, manifest []byte, ttl time.Duration) (err error)
+	// GetNotFound, SetNotFound и DeleteNotFound — отрицательный кеш: key отмечает отсутствующий проект,
+	// манифест версии или файл, DeleteNotFound снимает все отметки проекта.
+	GetNotFound(ctx context.Context, alias string, key string) (found bool, err error)
+	SetNotFound(ctx context.Context, alias string, key string, ttl time.Duration) (err error)
+	DeleteNotFound(ctx context.Context, alias string) (err error)
 	Clear(ctx context.Context) (err error)
 }
+
+// fileCache хранит содержимое файлов релизов; опубликованные артефакты неизменяемы, поэтому TTL не нужен.
+type fileCache interface {
+	OpenFile(ctx context.Context, alias string, version string, filename string) (body io.ReadSeekCloser, info domain.FileInfo, found bool, err error)
+	PutFile(ctx context.Context, alias string, version string, filename string, info domain.FileInfo, body io.Reader) (err error)
+	DeleteFiles(ctx context.Context, alias string) (err error)
+}
+
+// fileCacheLimit — необязательное расширение fileCache: сообщает, поместится ли файл такого размера в кеш.
+type fileCacheLimit interface {
+	Fits(size int64) (ok bool)
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+const verifyChunkSize = 32 * 1024
+
+// VerifyChecksums включает проверку файлов по контрольным суммам из манифеста версии.
+// При несовпадении поток обрывается с ErrChecksumMismatch до выдачи последнего блока и файл не попадает в кеш.
+// Диапазоны (Range) с проверкой отдаются только из файлового кеша; файл, которого там ещё нет, отдаётся целиком.
+// Сумма в неизвестном формате — ошибка ErrUnsupportedChecksum: файл не отдаётся непроверенным.
+func VerifyChecksums() (opt EngineOption) {
+	return func(e *engine) {
+		e.verifyChecksums = true
+	}
+}
+
+// expectedChecksum ищет контрольную сумму файла в манифесте: по имени файла или по URL, который источник разбирает в то же имя.
+// Манифест берётся через кеш движка без подмены URL (пустой baseURL), поэтому ссылки в нём остаются ссылками источника.
+func (e *engine) expectedChecksum(ctx context.Context, src Source, project domain.Project, alias string, version string, filename string) (checksum string, err error) {
+
+	var manifest *model.Manifest
+	if manifest, err = e.loadManifest(ctx, project, alias, version, ""); err != nil {
+		if errors.Is(err, errs.ErrVersionNotFound) {
+			err = nil
+		}
+		return
+	}
+
+	for _, pkg := range manifest.Packages {
+		for _, f := range pkg.Files {
+			if f.Checksum == "" {
+				continue
+			}
+			for _, candidate := range []string{f.Source, f.File} {
+				if candidate == filename {
+					return f.Checksum, nil
+				}
+				if fileVersion, name, ok := src.ParseFileURL(candidate); ok && fileVersion == version && name == filename {
+					return f.Checksum, nil
+				}
+			}
+		}
+	}
+	return
+}
+
+// verifyingReader хеширует поток и придерживает последний прочитанный блок, пока не сверит сумму на EOF:
+// клиент не получает файл целиком, если содержимое подменено.
+type verifyingReader struct {
+	body       io.ReadCloser
+	hash       hash.Hash
+	expected   []byte
+	held       []byte
+	spare      []byte
+	ready      []byte
+	verified   bool
+	err        error
+	onMismatch func(actual string)
+}
+
+func newVerifyingReader(body io.ReadCloser, checksum string, onMismatch func(actual string)) (r *verifyingReader, err error) {
+
+	var algorithm string
+	var expected []byte
+	if algorithm, expected, err = helpers.ParseChecksum(checksum); err != nil {
+		return
+	}
+
+	var h hash.Hash
+	if h, err = helpers.NewChecksumHash(algorithm); err != nil {
+		return
+	}
+
+	r = &verifyingReader{
+		body:       body,
+		hash:       h,
+		expected:   expected,
+		held:       make([]byte, 0, verifyChunkSize),
+		spare:      make([]byte, verifyChunkSize),
+		onMismatch: onMismatch,
+	}
+	return
+}
+
+func (r *verifyingReader) Read(p []byte) (n int, err error) {
+
+	for len(r.ready) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+
+	n = copy(p, r.ready)
+	r.ready = r.ready[n:]
+	return
+}
+
+func (r *verifyingReader) fill() {
+
+	if r.verified {
+		if len(r.held) > 0 {
+			r.ready, r.held = r.held, nil
+			return
+		}
+		r.err = io.EOF
+		return
+	}
+
+	n, err := r.body.Read(r.spare[:cap(r.spare)])
+	if n > 0 {
+		_, _ = r.hash.Write(r.spare[:n])
+		// ready отдаётся клиенту до следующего fill, поэтому буфер предыдущего блока можно переиспользовать
+		r.ready = r.held
+		r.held, r.spare = r.spare[:n], r.held[:0]
+	}
+
+	switch {
+	case err == io.EOF:
+		if actual := r.hash.Sum(nil); !bytes.Equal(actual, r.expected) {
+			r.held = nil
+			r.err = fmt.Errorf("%w: expected %s, got %s", errs.ErrChecksumMismatch, hex.EncodeToString(r.expected), hex.EncodeToString(actual))
+			if r.onMismatch != nil {
+				r.onMismatch(hex.EncodeToString(actual))
+			}
+			return
+		}
+		r.verified = true
+	case err != nil:
+		r.err = err
+	}
+}
+
+func (r *verifyingReader) Close() (err error) {
+
+	return r.body.Close()
+}
+
+func (e *engine) verifyFileStream(ctx context.Context, alias string, version string, filename string, checksum string, body io.ReadCloser) (stream io.ReadCloser, err error) {
+
+	var r *verifyingReader
+	if r, err = newVerifyingReader(body, checksum, func(actual string) {
+		slog.ErrorContext(ctx, "File checksum mismatch, aborting response",
+			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyFilename, filename),
+			slog.String(helpers.LogKeyChecksum, checksum),
+			slog.String("actual_checksum", actual),
+		)
+	}); err != nil {
+		return
+	}
+	return r, nil
+}
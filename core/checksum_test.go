@@ -0,0 +1,108 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+)
+
+func sha256Checksum(content string) (checksum string) {
+
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// readVerified читает поток, как клиент: всё, что отдано до ошибки, считается полученным.
+func readVerified(t *testing.T, body io.Reader, checksum string) (received string, mismatch string, err error) {
+
+	t.Helper()
+
+	r, err := newVerifyingReader(io.NopCloser(body), checksum, func(actual string) {
+		mismatch = actual
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	return string(data), mismatch, err
+}
+
+func TestVerifyingReaderPassesMatchingFile(t *testing.T) {
+
+	content := strings.Repeat("0123456789", verifyChunkSize/4)
+
+	for name, body := range map[string]io.Reader{
+		"chunks":      strings.NewReader(content),
+		"single byte": iotest.OneByteReader(strings.NewReader(content)),
+		"data on EOF": iotest.DataErrReader(strings.NewReader(content)),
+	} {
+		received, mismatch, err := readVerified(t, body, sha256Checksum(content))
+		if err != nil || mismatch != "" {
+			t.Fatalf("%s: unexpected error %v (mismatch: %q)", name, err, mismatch)
+		}
+		if received != content {
+			t.Fatalf("%s: expected %d bytes, got %d", name, len(content), len(received))
+		}
+	}
+}
+
+func TestVerifyingReaderHoldsBackLastChunk(t *testing.T) {
+
+	tail := strings.Repeat("t", 100)
+	content := strings.Repeat("x", 3*verifyChunkSize) + tail
+	checksum := sha256Checksum("something else")
+
+	for name, body := range map[string]io.Reader{
+		"chunks":      strings.NewReader(content),
+		"data on EOF": iotest.DataErrReader(strings.NewReader(content)),
+	} {
+		received, mismatch, err := readVerified(t, body, checksum)
+		if !errors.Is(err, errs.ErrChecksumMismatch) {
+			t.Fatalf("%s: expected ErrChecksumMismatch, got %v", name, err)
+		}
+		if mismatch != sha256Checksum(content)[len("sha256:"):] {
+			t.Fatalf("%s: expected onMismatch with actual checksum, got %q", name, mismatch)
+		}
+		// последний прочитанный блок не отдаётся: клиент не получает файл целиком
+		if len(received) != 3*verifyChunkSize {
+			t.Fatalf("%s: expected %d bytes before error, got %d", name, 3*verifyChunkSize, len(received))
+		}
+	}
+}
+
+func TestVerifyingReaderSmallFileMismatch(t *testing.T) {
+
+	received, _, err := readVerified(t, strings.NewReader("tiny"), sha256Checksum("other"))
+	if !errors.Is(err, errs.ErrChecksumMismatch) || received != "" {
+		t.Fatalf("expected no bytes and ErrChecksumMismatch, got %q and %v", received, err)
+	}
+}
+
+func TestVerifyingReaderPropagatesReadError(t *testing.T) {
+
+	readErr := errors.New("connection reset")
+	body := io.MultiReader(strings.NewReader(strings.Repeat("x", 2*verifyChunkSize)), iotest.ErrReader(readErr))
+
+	received, mismatch, err := readVerified(t, body, sha256Checksum("whatever"))
+	if !errors.Is(err, readErr) || mismatch != "" {
+		t.Fatalf("expected read error without checksum check, got %v (mismatch: %q)", err, mismatch)
+	}
+	if len(received) != verifyChunkSize {
+		t.Fatalf("expected last chunk to be held back on read error, got %d bytes", len(received))
+	}
+}
+
+func TestVerifyingReaderUnsupportedChecksum(t *testing.T) {
+
+	for _, checksum := range []string{"sha3-256:abcd", "sha256:zz", "sha256:abcd"} {
+		if _, err := newVerifyingReader(io.NopCloser(strings.NewReader("")), checksum, nil); !errors.Is(err, errs.ErrUnsupportedChecksum) {
+			t.Errorf("%q: expected ErrUnsupportedChecksum, got %v", checksum, err)
+		}
+	}
+}
@@ -2,34 +2,51 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"sort"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 	"gopkg.in/yaml.v3"
 
 	"github.com/seniorGolang/tg-proxy/errs"
 	"github.com/seniorGolang/tg-proxy/helpers"
 	"github.com/seniorGolang/tg-proxy/model"
 	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/tracing"
 )
 
 const maxAggregateDepth = 10
 const listProjectsBatchSize = 500
 const aggregateManifestTTL = 5 * time.Minute
+const defaultManifestTTL = time.Hour
 
 type engine struct {
-	storage     storage
-	encryptor   encryptor
-	cache       cache
-	sources     map[string]Source
-	sourcesMu   sync.RWMutex
-	resolver    *resolver
-	transformer *transformer
+	storage            storage
+	encryptor          encryptor
+	cache              cache
+	fileCache          fileCache
+	bus                invalidationBus
+	observer           observer
+	tracerProvider     trace.TracerProvider
+	tracer             trace.Tracer
+	healthCheckTimeout time.Duration
+	instanceID         string
+	sources            map[string]Source
+	sourcesMu          sync.RWMutex
+	flight             singleflight.Group
+	aliasFlights       aliasFlights
+	staleFailures      sync.Map
+	resolver           *resolver
+	transformer        *transformer
+	manifestTTL        time.Duration
+	notFoundTTL        time.Duration
+	verifyChecksums    bool
 }
 
 type EngineOption func(*engine)
@@ -52,18 +69,49 @@ func Cache(c cache) (opt EngineOption) {
 	}
 }
 
+// FileCache включает локальный кеш содержимого файлов релизов.
+func FileCache(fc fileCache) (opt EngineOption) {
+	return func(e *engine) {
+		e.fileCache = fc
+	}
+}
+
+// ManifestTTL задаёт срок хранения манифестов версий после замены URL (по умолчанию час); 0 отключает кеширование.
+// Манифест тега почти не меняется, но ссылки на зависимости зависят от списка проектов, поэтому срок конечный.
+func ManifestTTL(ttl time.Duration) (opt EngineOption) {
+	return func(e *engine) {
+		e.manifestTTL = ttl
+	}
+}
+
 func NewEngine(opts ...EngineOption) (eng *engine) {
 
 	e := &engine{
-		sources: make(map[string]Source),
+		instanceID:         uuid.NewString(),
+		sources:            make(map[string]Source),
+		manifestTTL:        defaultManifestTTL,
+		notFoundTTL:        defaultNotFoundTTL,
+		healthCheckTimeout: defaultHealthCheckTimeout,
 	}
 
 	for _, opt := range opts {
 		opt(e)
 	}
 
-	e.resolver = newResolver(e.storage, e.encryptor, e.cache)
-	e.transformer = newTransformer(e.storage)
+	e.tracer = tracing.Tracer(e.tracerProvider)
+	e.traceDependencies()
+
+	e.resolver = newResolver(e.storage, e.encryptor, e.cache, e.notFoundTTL, e.observer, e.tracer)
+	e.transformer = newTransformer(e.storage, e.tracer)
+
+	if e.bus != nil {
+		if err := e.bus.Subscribe(e.handleInvalidation); err != nil {
+			slog.Warn("Failed to subscribe to cache invalidations",
+				slog.String(helpers.LogKeyAction, helpers.ActionInvalidateCache),
+				slog.Any(helpers.LogKeyError, err),
+			)
+		}
+	}
 
 	return e
 }
@@ -125,10 +173,13 @@ func (e *engine) GetManifestData(ctx context.Context, alias string, version stri
 
 func (e *engine) getManifestData(ctx context.Context, alias string, version string, baseURL string) (m *model.Manifest, err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.GetManifest", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version)))
+	defer endSpan(span, &err)
+
 	var found bool
 	var project domain.Project
 	if project, found, err = e.resolver.ResolveProject(ctx, alias); err != nil {
-		slog.Debug("Failed to resolve project",
+		slog.DebugContext(ctx, "Failed to resolve project",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -137,7 +188,7 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 		return
 	}
 	if !found {
-		slog.Debug("Project not found",
+		slog.DebugContext(ctx, "Project not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -146,7 +197,7 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 		return
 	}
 
-	slog.Debug("Project resolved",
+	slog.DebugContext(ctx, "Project resolved",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
@@ -154,38 +205,49 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 		slog.String(helpers.LogKeyRepoURL, project.RepoURL),
 	)
 
-	var availableVersions []string
-	if availableVersions, err = e.GetVersions(ctx, alias); err != nil {
-		slog.Debug("Failed to get versions",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
-			slog.Any(helpers.LogKeyError, err),
-		)
+	if version, err = e.ResolveVersion(ctx, alias, version); err != nil {
 		return
 	}
 
-	var versionExists bool
-	for _, v := range availableVersions {
-		if v == version {
-			versionExists = true
-			break
+	return e.loadManifest(ctx, project, alias, version, baseURL)
+}
+
+// loadManifest отдаёт манифест точной версии из кеша, а при промахе загружает его из источника одним запросом
+// на все конкурентные обращения; отсутствие манифеста запоминается в кеше отметок.
+func (e *engine) loadManifest(ctx context.Context, project domain.Project, alias string, version string, baseURL string) (m *model.Manifest, err error) {
+
+	key := flightKey("manifest", alias, version, baseURL)
+	fetch := func(ctx context.Context) (*model.Manifest, error) {
+		defer e.aliasFlights.track(alias, key)()
+		return e.fetchManifest(ctx, project, alias, version, baseURL)
+	}
+
+	var stale, found bool
+	if m, stale, found = e.getCachedManifest(ctx, alias, version, baseURL); found {
+		if stale {
+			revalidate(ctx, &e.flight, &e.staleFailures, key, fetch)
 		}
+		return
 	}
-	if !versionExists {
-		slog.Debug("Version not found",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
-			slog.Int(helpers.LogKeyVersionsCount, len(availableVersions)),
-		)
+
+	if isNotFoundCached(ctx, e.cache, e.observer, e.notFoundTTL, alias, notFoundManifestKey(version)) {
 		err = errs.ErrVersionNotFound
 		return
 	}
 
+	if m, _, err = coalesce(ctx, &e.flight, key, fetch); err != nil {
+		return
+	}
+	e.staleFailures.Delete(key)
+	return
+}
+
+// fetchManifest загружает манифест из источника и заменяет в нём URL; результат сохраняется в кеш.
+func (e *engine) fetchManifest(ctx context.Context, project domain.Project, alias string, version string, baseURL string) (m *model.Manifest, err error) {
+
 	var src Source
 	if src, err = e.GetSource(project.SourceName); err != nil {
-		slog.Debug("Source not found",
+		slog.DebugContext(ctx, "Source not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -196,18 +258,23 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 	}
 
 	var domainManifest domain.Manifest
-	if domainManifest, err = src.GetManifest(ctx, project, version); err != nil {
-		if statusCode, found := helpers.ExtractStatusCode(err); found && statusCode == 404 {
-			slog.Debug("Manifest not found (404), treating as version not found",
+	sourceCtx, done := e.startUpstream(ctx, project.SourceName, upstreamGetManifest)
+	domainManifest, err = src.GetManifest(sourceCtx, project, version)
+	done(err)
+	if err != nil {
+		// ErrFileNotFound — источник нашёл релиз, но манифеста в нём нет: для прокси это тоже отсутствие версии
+		if statusCode, found := helpers.ExtractStatusCode(err); (found && statusCode == 404) || errors.Is(err, errs.ErrFileNotFound) {
+			slog.DebugContext(ctx, "Manifest not found, treating as version not found",
 				slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 				slog.String(helpers.LogKeyAlias, alias),
 				slog.String(helpers.LogKeyVersion, version),
 				slog.String(helpers.LogKeySource, project.SourceName),
 			)
 			err = errs.ErrVersionNotFound
+			cacheNotFound(ctx, e.cache, e.notFoundTTL, alias, notFoundManifestKey(version))
 			return
 		}
-		slog.Debug("Failed to get manifest from source",
+		slog.DebugContext(ctx, "Failed to get manifest from source",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -222,7 +289,7 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 
 	var sourceDomain string
 	if sourceDomain, err = ExtractSourceDomain(project.RepoURL); err != nil {
-		slog.Debug("Failed to extract source domain, continuing without domain check",
+		slog.DebugContext(ctx, "Failed to extract source domain, continuing without domain check",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -233,7 +300,7 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 	}
 
 	if err = e.transformer.ReplaceManifestURLs(ctx, &modelManifest, alias, version, baseURL, sourceDomain, src); err != nil {
-		slog.Debug("Failed to transform manifest",
+		slog.DebugContext(ctx, "Failed to transform manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -243,11 +310,60 @@ func (e *engine) getManifestData(ctx context.Context, alias string, version stri
 	}
 
 	m = &modelManifest
+	e.setCachedManifest(ctx, alias, version, baseURL, m)
 	return
 }
 
+func (e *engine) getCachedManifest(ctx context.Context, alias string, version string, baseURL string) (m *model.Manifest, stale bool, found bool) {
+
+	if e.cache == nil || e.manifestTTL <= 0 {
+		return
+	}
+
+	data, found, stale, err := e.cache.GetManifest(ctx, alias, version, baseURL)
+	e.observer.cache(cacheKindManifest, err == nil && found)
+	if err != nil || !found {
+		return nil, false, false
+	}
+
+	var cached model.Manifest
+	if err = yaml.Unmarshal(data, &cached); err != nil {
+		slog.DebugContext(ctx, "Failed to decode cached manifest",
+			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
+			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyVersion, version),
+			slog.Any(helpers.LogKeyError, err),
+		)
+		return nil, false, false
+	}
+
+	slog.DebugContext(ctx, "Manifest retrieved from cache",
+		slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
+		slog.String(helpers.LogKeyAlias, alias),
+		slog.String(helpers.LogKeyVersion, version),
+		slog.Bool("stale", stale),
+	)
+	return &cached, stale, true
+}
+
+func (e *engine) setCachedManifest(ctx context.Context, alias string, version string, baseURL string, m *model.Manifest) {
+
+	if e.cache == nil || e.manifestTTL <= 0 {
+		return
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return
+	}
+	_ = e.cache.SetManifest(ctx, alias, version, baseURL, data, e.manifestTTL)
+}
+
 func (e *engine) GetManifestAggregated(ctx context.Context, alias string, version string, baseURL string) (out *model.ManifestAggregatedResponse, err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.GetManifestAggregated", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version)))
+	defer endSpan(span, &err)
+
 	visited := make(map[string]bool)
 	var packages []model.PackageWithSource
 	var versionOut string
@@ -267,6 +383,9 @@ func (e *engine) collectAggregatedPackages(ctx context.Context, alias string, ve
 	if depth > maxDepth {
 		return version, nil, nil
 	}
+	if version, err = e.ResolveVersion(ctx, alias, version); err != nil {
+		return
+	}
 	key := alias + "/" + version
 	if visited[key] {
 		return version, nil, nil
@@ -276,7 +395,7 @@ func (e *engine) collectAggregatedPackages(ctx context.Context, alias string, ve
 
 	var m *model.Manifest
 	if m, err = e.getManifestData(ctx, alias, version, baseURL); err != nil {
-		slog.Debug("Failed to get manifest for aggregation",
+		slog.DebugContext(ctx, "Failed to get manifest for aggregation",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifestAggregated),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -309,10 +428,15 @@ func (e *engine) collectAggregatedPackages(ctx context.Context, alias string, ve
 
 func (e *engine) GetAggregateManifest(ctx context.Context, baseURL string) (manifest []byte, err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.GetAggregateManifest")
+	defer endSpan(span, &err)
+
 	if e.cache != nil {
 		var found bool
-		if manifest, found, err = e.cache.GetAggregateManifest(ctx); err != nil {
-			slog.Debug("Failed to get aggregate manifest from cache",
+		manifest, found, err = e.cache.GetAggregateManifest(ctx)
+		e.observer.cache(cacheKindAggregate, err == nil && found)
+		if err != nil {
+			slog.DebugContext(ctx, "Failed to get aggregate manifest from cache",
 				slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 				slog.Any(helpers.LogKeyError, err),
 			)
@@ -324,7 +448,7 @@ func (e *engine) GetAggregateManifest(ctx context.Context, baseURL string) (mani
 
 	var version string
 	if version, err = e.storage.GetCatalogVersion(ctx); err != nil {
-		slog.Debug("Failed to get catalog version",
+		slog.DebugContext(ctx, "Failed to get catalog version",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Any(helpers.LogKeyError, err),
 		)
@@ -333,7 +457,7 @@ func (e *engine) GetAggregateManifest(ctx context.Context, baseURL string) (mani
 
 	projects, err := e.listAllProjectsForAggregate(ctx)
 	if err != nil {
-		slog.Debug("Failed to list projects for aggregate manifest",
+		slog.DebugContext(ctx, "Failed to list projects for aggregate manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Any(helpers.LogKeyError, err),
 		)
@@ -355,7 +479,7 @@ func (e *engine) GetAggregateManifest(ctx context.Context, baseURL string) (mani
 	}
 
 	if manifest, err = yaml.Marshal(&agg); err != nil {
-		slog.Debug("Failed to marshal aggregate manifest",
+		slog.DebugContext(ctx, "Failed to marshal aggregate manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Any(helpers.LogKeyError, err),
 		)
@@ -391,12 +515,32 @@ func (e *engine) GetCatalogVersion(ctx context.Context) (version string, err err
 	return e.storage.GetCatalogVersion(ctx)
 }
 
-func (e *engine) GetFile(ctx context.Context, alias string, version string, filename string) (stream io.ReadCloser, err error) {
+// GetFile отдаёт файл версии проекта. header — Range и условные заголовки клиента: если источник их поддерживает,
+// они передаются ему, и ответ может прийти со статусом 206 или 304.
+func (e *engine) GetFile(ctx context.Context, alias string, version string, filename string, header http.Header) (file *File, err error) {
+
+	ctx, span := e.tracer.Start(ctx, "engine.GetFile", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version), attrFilename.String(filename)))
+	defer endSpan(span, &err)
+
+	return e.getFile(ctx, alias, version, filename, header, false)
+}
+
+// HeadFile отдаёт метаданные файла без тела: из кеша, если файл там есть, иначе запросом HEAD к источнику.
+// Тело у возвращённого File пустое (или не читается), контрольная сумма не проверяется, в кеш ничего не пишется.
+func (e *engine) HeadFile(ctx context.Context, alias string, version string, filename string, header http.Header) (file *File, err error) {
+
+	ctx, span := e.tracer.Start(ctx, "engine.HeadFile", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version), attrFilename.String(filename)))
+	defer endSpan(span, &err)
+
+	return e.getFile(ctx, alias, version, filename, header, true)
+}
+
+func (e *engine) getFile(ctx context.Context, alias string, version string, filename string, header http.Header, head bool) (file *File, err error) {
 
 	var found bool
 	var project domain.Project
 	if project, found, err = e.resolver.ResolveProject(ctx, alias); err != nil {
-		slog.Debug("Failed to resolve project",
+		slog.DebugContext(ctx, "Failed to resolve project",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -406,7 +550,7 @@ func (e *engine) GetFile(ctx context.Context, alias string, version string, file
 		return
 	}
 	if !found {
-		slog.Debug("Project not found",
+		slog.DebugContext(ctx, "Project not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -416,42 +560,32 @@ func (e *engine) GetFile(ctx context.Context, alias string, version string, file
 		return
 	}
 
-	var availableVersions []string
-	if availableVersions, err = e.GetVersions(ctx, alias); err != nil {
-		slog.Debug("Failed to get versions",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
-			slog.String(helpers.LogKeyFilename, filename),
-			slog.Any(helpers.LogKeyError, err),
-		)
+	// точный тег ищем в кеше до обращения к источнику — так файл отдаётся, даже если источник недоступен
+	if file, found = e.openCachedFile(ctx, alias, version, filename); found {
 		return
 	}
 
-	var versionExists bool
-	for _, v := range availableVersions {
-		if v == version {
-			versionExists = true
-			break
+	var resolved string
+	if resolved, err = e.ResolveVersion(ctx, alias, version); err != nil {
+		return
+	}
+	if resolved != version {
+		if file, found = e.openCachedFile(ctx, alias, resolved, filename); found {
+			return
 		}
 	}
-	if !versionExists {
-		slog.Debug("Version not found",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
-			slog.String(helpers.LogKeyFilename, filename),
-		)
-		err = errs.ErrVersionNotFound
+
+	if isNotFoundCached(ctx, e.cache, e.observer, e.notFoundTTL, alias, notFoundFileKey(resolved, filename)) {
+		err = errs.ErrFileNotFound
 		return
 	}
 
 	var src Source
 	if src, err = e.GetSource(project.SourceName); err != nil {
-		slog.Debug("Source not found",
+		slog.DebugContext(ctx, "Source not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyVersion, resolved),
 			slog.String(helpers.LogKeyFilename, filename),
 			slog.String(helpers.LogKeySource, project.SourceName),
 			slog.Any(helpers.LogKeyError, err),
@@ -459,39 +593,267 @@ func (e *engine) GetFile(ctx context.Context, alias string, version string, file
 		return
 	}
 
-	if stream, err = src.GetFileStream(ctx, project, version, filename); err != nil {
-		// 404 от API → ErrVersionNotFound (версия уже проверена в списке версий)
-		if statusCode, found := helpers.ExtractStatusCode(err); found && statusCode == 404 {
-			slog.Debug("File not found (404), treating as version not found",
+	if head {
+		if file, err = e.headFile(ctx, src, project, resolved, filename, e.fileRequestHeader(header)); err != nil {
+			if errors.Is(err, errs.ErrFileNotFound) {
+				cacheNotFound(ctx, e.cache, e.notFoundTTL, alias, notFoundFileKey(resolved, filename))
+			}
+		}
+		return
+	}
+
+	var checksum string
+	if e.verifyChecksums {
+		if checksum, err = e.expectedChecksum(ctx, src, project, alias, resolved, filename); err != nil {
+			slog.DebugContext(ctx, "Failed to get manifest for checksum verification",
 				slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 				slog.String(helpers.LogKeyAlias, alias),
-				slog.String(helpers.LogKeyVersion, version),
+				slog.String(helpers.LogKeyVersion, resolved),
 				slog.String(helpers.LogKeyFilename, filename),
 				slog.String(helpers.LogKeySource, project.SourceName),
+				slog.Any(helpers.LogKeyError, err),
 			)
-			err = errs.ErrVersionNotFound
 			return
 		}
-		slog.Debug("Failed to get file stream from source",
+	}
+
+	// при Range источник может прислать файл целиком: остаток потока докачивается в кеш уже после ответа клиенту,
+	// поэтому такой запрос не должен обрываться вместе с клиентским контекстом.
+	// Проверяемый файл диапазоном не отдаётся (см. File.Verifying) — клиент читает его до конца сам
+	rangeFromStream := header.Get("Range") != "" && e.fileCache != nil && checksum == ""
+	fetchCtx := ctx
+	if rangeFromStream {
+		fetchCtx = context.WithoutCancel(ctx)
+	}
+	if file, err = e.fetchFile(fetchCtx, src, project, resolved, filename, checksum, e.fileRequestHeader(header)); err != nil {
+		if errors.Is(err, errs.ErrFileNotFound) {
+			cacheNotFound(ctx, e.cache, e.notFoundTTL, alias, notFoundFileKey(resolved, filename))
+		}
+		return
+	}
+
+	if !rangeFromStream || file.StatusCode != http.StatusOK {
+		return
+	}
+
+	// источник отдал файл целиком — диапазон вырезается из живого потока, а остаток после ответа
+	// дочитывается в фоне, чтобы файл попал в кеш; файл, который в кеш не поместится, не докачивается
+	if fitsFileCache(e.fileCache, file.ContentLength) {
+		file.Body = &drainingBody{body: file.Body}
+	}
+	return
+}
+
+// headFile запрашивает у источника только заголовки файла; источник без HeadFileSource отвечает обычным GET,
+// тело которого закрывается непрочитанным.
+func (e *engine) headFile(ctx context.Context, src Source, project domain.Project, version string, filename string, header http.Header) (file *File, err error) {
+
+	headSource, ok := src.(HeadFileSource)
+	if !ok {
+		return e.fetchFile(ctx, src, project, version, filename, "", header)
+	}
+
+	var resp *http.Response
+	sourceCtx, done := e.startUpstream(ctx, project.SourceName, upstreamGetFile)
+	resp, err = headSource.HeadFileResponse(sourceCtx, project, version, filename, header)
+	done(err)
+	if err = e.fileResponseError(ctx, project, version, filename, err); err != nil {
+		return
+	}
+
+	file = newUpstreamFile(resp, version)
+	return
+}
+
+func (e *engine) fetchFile(ctx context.Context, src Source, project domain.Project, version string, filename string, checksum string, header http.Header) (file *File, err error) {
+
+	var resp *http.Response
+	sourceCtx, done := e.startUpstream(ctx, project.SourceName, upstreamGetFile)
+	if requestSource, ok := src.(FileRequestSource); ok && header != nil {
+		resp, err = requestSource.GetFileResponseWithHeader(sourceCtx, project, version, filename, header)
+	} else {
+		resp, err = src.GetFileResponse(sourceCtx, project, version, filename)
+	}
+	done(err)
+	if err = e.fileResponseError(ctx, project, version, filename, err); err != nil {
+		return
+	}
+
+	file = newUpstreamFile(resp, version)
+	if resp.StatusCode != http.StatusOK {
+		// 206 и 304 — ответ на переданные заголовки: содержимое неполное, проверять и кешировать нечего
+		return
+	}
+
+	if checksum != "" {
+		if file.Body, err = e.verifyFileStream(ctx, project.Alias, version, filename, checksum, resp.Body); err != nil {
+			_ = resp.Body.Close()
+			slog.WarnContext(ctx, "Unsupported checksum in manifest",
+				slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+				slog.String(helpers.LogKeyAlias, project.Alias),
+				slog.String(helpers.LogKeyVersion, version),
+				slog.String(helpers.LogKeyFilename, filename),
+				slog.String(helpers.LogKeyChecksum, checksum),
+				slog.Any(helpers.LogKeyError, err),
+			)
+			file = nil
+			return
+		}
+		file.Verifying = true
+	}
+
+	// проверка стоит перед кешем: при несовпадении суммы кеш получает ошибку вместо EOF и запись отменяется
+	if e.fileCache != nil {
+		file.Body = e.cacheFileStream(ctx, project.Alias, version, filename, file)
+	}
+
+	return
+}
+
+// fileResponseError переводит ошибку запроса файла к источнику: 404 становится ErrFileNotFound.
+func (e *engine) fileResponseError(ctx context.Context, project domain.Project, version string, filename string, sourceErr error) (err error) {
+
+	if err = sourceErr; err == nil {
+		return
+	}
+
+	// 404 от API → ErrFileNotFound (версия уже проверена в списке версий)
+	if statusCode, found := helpers.ExtractStatusCode(err); found && statusCode == 404 {
+		slog.DebugContext(ctx, "File not found (404)",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyAlias, project.Alias),
 			slog.String(helpers.LogKeyVersion, version),
 			slog.String(helpers.LogKeyFilename, filename),
 			slog.String(helpers.LogKeySource, project.SourceName),
+		)
+		err = errs.ErrFileNotFound
+		return
+	}
+	slog.DebugContext(ctx, "Failed to get file from source",
+		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+		slog.String(helpers.LogKeyAlias, project.Alias),
+		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyFilename, filename),
+		slog.String(helpers.LogKeySource, project.SourceName),
+		slog.Any(helpers.LogKeyError, err),
+	)
+	return
+}
+
+// fileRequestHeader отбирает заголовки клиента для передачи источнику; nil — передавать нечего.
+func (e *engine) fileRequestHeader(header http.Header) (upstream http.Header) {
+
+	for _, key := range helpers.FileRequestHeaders {
+		// частичное содержимое не сверить с контрольной суммой
+		if e.verifyChecksums && (key == "Range" || key == "If-Range") {
+			continue
+		}
+		if value := header.Get(key); value != "" {
+			if upstream == nil {
+				upstream = make(http.Header)
+			}
+			upstream.Set(key, value)
+		}
+	}
+	return
+}
+
+func (e *engine) openCachedFile(ctx context.Context, alias string, version string, filename string) (file *File, found bool) {
+
+	if e.fileCache == nil {
+		return
+	}
+
+	body, info, found, err := e.fileCache.OpenFile(ctx, alias, version, filename)
+	e.observer.cache(cacheKindFile, err == nil && found)
+	if err != nil {
+		slog.DebugContext(ctx, "Failed to open file from cache",
+			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyFilename, filename),
+			slog.Any(helpers.LogKeyError, err),
+		)
+		return nil, false
+	}
+	if !found {
+		return
+	}
+
+	slog.DebugContext(ctx, "File served from cache",
+		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+		slog.String(helpers.LogKeyAlias, alias),
+		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyFilename, filename),
+		slog.String(helpers.LogKeyDigest, info.Digest),
+	)
+
+	file = &File{
+		Body:          body,
+		Version:       version,
+		StatusCode:    http.StatusOK,
+		ContentType:   info.ContentType,
+		ContentLength: info.Size,
+		ETag:          info.ETag,
+		LastModified:  info.ModTime,
+		Cached:        true,
+	}
+	if file.ETag == "" {
+		file.ETag = `"` + info.Digest + `"`
+	}
+	return
+}
+
+// ResolveVersion приводит версию из запроса (тег, latest, latest-stable или диапазон вроде ^1.4) к конкретному тегу проекта.
+func (e *engine) ResolveVersion(ctx context.Context, alias string, version string) (resolved string, err error) {
+
+	ctx, span := e.tracer.Start(ctx, "engine.ResolveVersion", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version)))
+	defer endSpan(span, &err)
+
+	var availableVersions []string
+	if availableVersions, err = e.GetVersions(ctx, alias); err != nil {
+		slog.DebugContext(ctx, "Failed to get versions",
+			slog.String(helpers.LogKeyAction, helpers.ActionResolveVersion),
+			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyVersion, version),
 			slog.Any(helpers.LogKeyError, err),
 		)
 		return
 	}
 
+	var found bool
+	if resolved, found = helpers.ResolveVersion(availableVersions, version); !found {
+		slog.DebugContext(ctx, "Version not found",
+			slog.String(helpers.LogKeyAction, helpers.ActionResolveVersion),
+			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyVersion, version),
+			slog.Int(helpers.LogKeyVersionsCount, len(availableVersions)),
+		)
+		err = errs.ErrVersionNotFound
+		return
+	}
+
+	if resolved != version {
+		slog.DebugContext(ctx, "Version resolved",
+			slog.String(helpers.LogKeyAction, helpers.ActionResolveVersion),
+			slog.String(helpers.LogKeyAlias, alias),
+			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyResolvedVersion, resolved),
+		)
+	}
+
 	return
 }
 
 func (e *engine) GetVersions(ctx context.Context, alias string) (versions []string, err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.GetVersions", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
 	var project domain.Project
 	var found bool
 	if project, found, err = e.resolver.ResolveProject(ctx, alias); err != nil {
-		slog.Debug("Failed to resolve project",
+		slog.DebugContext(ctx, "Failed to resolve project",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Any(helpers.LogKeyError, err),
@@ -499,7 +861,7 @@ func (e *engine) GetVersions(ctx context.Context, alias string) (versions []stri
 		return
 	}
 	if !found {
-		slog.Debug("Project not found",
+		slog.DebugContext(ctx, "Project not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 		)
@@ -507,27 +869,49 @@ func (e *engine) GetVersions(ctx context.Context, alias string) (versions []stri
 		return
 	}
 
+	key := flightKey("versions", alias)
+	fetch := func(ctx context.Context) ([]string, error) {
+		defer e.aliasFlights.track(alias, key)()
+		return e.fetchVersions(ctx, project, alias)
+	}
+
 	var cachedVersions []string
-	var cachedFound bool
-	if cachedVersions, cachedFound, err = e.cache.GetVersions(ctx, alias); err == nil && cachedFound {
-		slog.Debug("Versions retrieved from cache",
+	var cachedFound, stale bool
+	cachedVersions, cachedFound, stale, err = e.cache.GetVersions(ctx, alias)
+	e.observer.cache(cacheKindVersions, err == nil && cachedFound)
+	if err == nil && cachedFound {
+		slog.DebugContext(ctx, "Versions retrieved from cache",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyVersionsCount, len(cachedVersions)),
+			slog.Bool("stale", stale),
 		)
+		if stale {
+			revalidate(ctx, &e.flight, &e.staleFailures, key, fetch)
+		}
 		versions = cachedVersions
 		return
 	}
 
-	slog.Debug("Cache miss, fetching from source",
+	slog.DebugContext(ctx, "Cache miss, fetching from source",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeySource, project.SourceName),
 	)
 
+	if versions, _, err = coalesce(ctx, &e.flight, key, fetch); err != nil {
+		return
+	}
+	e.staleFailures.Delete(key)
+	return
+}
+
+// fetchVersions загружает теги из источника, сортирует их и сохраняет в кеш.
+func (e *engine) fetchVersions(ctx context.Context, project domain.Project, alias string) (versions []string, err error) {
+
 	var src Source
 	if src, err = e.GetSource(project.SourceName); err != nil {
-		slog.Debug("Source not found",
+		slog.DebugContext(ctx, "Source not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeySource, project.SourceName),
@@ -536,10 +920,13 @@ func (e *engine) GetVersions(ctx context.Context, alias string) (versions []stri
 		return
 	}
 
-	if versions, err = src.GetVersions(ctx, project); err != nil {
+	sourceCtx, done := e.startUpstream(ctx, project.SourceName, upstreamGetVersions)
+	versions, err = src.GetVersions(sourceCtx, project)
+	done(err)
+	if err != nil {
 		// 404 от API → пустой список версий (вместо ошибки)
 		if statusCode, found := helpers.ExtractStatusCode(err); found && statusCode == 404 {
-			slog.Debug("No packages found (404), returning empty versions list",
+			slog.DebugContext(ctx, "No packages found (404), returning empty versions list",
 				slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 				slog.String(helpers.LogKeyAlias, alias),
 				slog.String(helpers.LogKeySource, project.SourceName),
@@ -548,7 +935,7 @@ func (e *engine) GetVersions(ctx context.Context, alias string) (versions []stri
 			err = nil
 			return
 		}
-		slog.Debug("Failed to get versions from source",
+		slog.DebugContext(ctx, "Failed to get versions from source",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeySource, project.SourceName),
@@ -557,15 +944,20 @@ func (e *engine) GetVersions(ctx context.Context, alias string) (versions []stri
 		return
 	}
 
-	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	helpers.SortVersions(versions)
 
 	_ = e.cache.SetVersions(ctx, alias, versions, 5*time.Minute)
+	// в свежем списке могут быть только что опубликованные версии — прежние отметки об отсутствии больше не верны
+	_ = e.cache.DeleteNotFound(ctx, alias)
 
 	return
 }
 
 func (e *engine) CreateProject(ctx context.Context, project domain.Project) (id uuid.UUID, err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.CreateProject", trace.WithAttributes(attrAlias.String(project.Alias)))
+	defer endSpan(span, &err)
+
 	if project.ID == uuid.Nil {
 		project.ID = uuid.New()
 	}
@@ -574,7 +966,7 @@ func (e *engine) CreateProject(ctx context.Context, project domain.Project) (id
 	if project.Token != "" && e.encryptor != nil {
 		var encryptedToken string
 		if encryptedToken, err = e.encryptor.EncryptString(project.Token); err != nil {
-			slog.Debug("Failed to encrypt token",
+			slog.DebugContext(ctx, "Failed to encrypt token",
 				slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 				slog.String(helpers.LogKeyAlias, project.Alias),
 				slog.Any(helpers.LogKeyError, err),
@@ -586,7 +978,7 @@ func (e *engine) CreateProject(ctx context.Context, project domain.Project) (id
 	}
 
 	if id, err = e.storage.CreateProject(ctx, project); err != nil {
-		slog.Debug("Failed to create project in storage",
+		slog.DebugContext(ctx, "Failed to create project in storage",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyAlias, project.Alias),
 			slog.Any(helpers.LogKeyError, err),
@@ -594,7 +986,7 @@ func (e *engine) CreateProject(ctx context.Context, project domain.Project) (id
 		return uuid.Nil, err
 	}
 
-	_ = e.resolver.InvalidateCache(ctx, project.Alias)
+	e.invalidate(ctx, projectInvalidations(project.Alias, false)...)
 
 	return id, nil
 }
@@ -617,7 +1009,7 @@ func (e *engine) GetProjectByID(ctx context.Context, id uuid.UUID) (project doma
 	if project.EncryptedToken != "" && e.encryptor != nil {
 		var token string
 		if token, err = e.encryptor.DecryptString(project.EncryptedToken); err != nil {
-			slog.Debug("Failed to decrypt token",
+			slog.DebugContext(ctx, "Failed to decrypt token",
 				slog.String(helpers.LogKeyAction, helpers.ActionResolveProject),
 				slog.String(helpers.LogKeyAlias, project.Alias),
 				slog.Any(helpers.LogKeyError, err),
@@ -639,13 +1031,16 @@ func (e *engine) GetProjectByID(ctx context.Context, id uuid.UUID) (project doma
 
 func (e *engine) UpdateProject(ctx context.Context, alias string, project domain.Project) (err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.UpdateProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
 	project.Alias = alias
 	project.RepoURL = helpers.NormalizeRepoURL(project.RepoURL)
 
 	if project.Token != "" && e.encryptor != nil {
 		var encryptedToken string
 		if encryptedToken, err = e.encryptor.EncryptString(project.Token); err != nil {
-			slog.Debug("Failed to encrypt token",
+			slog.DebugContext(ctx, "Failed to encrypt token",
 				slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 				slog.String(helpers.LogKeyAlias, alias),
 				slog.Any(helpers.LogKeyError, err),
@@ -657,7 +1052,7 @@ func (e *engine) UpdateProject(ctx context.Context, alias string, project domain
 	}
 
 	if err = e.storage.UpdateProject(ctx, alias, project); err != nil {
-		slog.Debug("Failed to update project in storage",
+		slog.DebugContext(ctx, "Failed to update project in storage",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Any(helpers.LogKeyError, err),
@@ -665,15 +1060,18 @@ func (e *engine) UpdateProject(ctx context.Context, alias string, project domain
 		return
 	}
 
-	_ = e.resolver.InvalidateCache(ctx, alias)
+	e.invalidate(ctx, projectInvalidations(alias, true)...)
 
 	return
 }
 
 func (e *engine) DeleteProject(ctx context.Context, alias string) (err error) {
 
+	ctx, span := e.tracer.Start(ctx, "engine.DeleteProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
 	if err = e.storage.DeleteProject(ctx, alias); err != nil {
-		slog.Debug("Failed to delete project from storage",
+		slog.DebugContext(ctx, "Failed to delete project from storage",
 			slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Any(helpers.LogKeyError, err),
@@ -681,7 +1079,7 @@ func (e *engine) DeleteProject(ctx context.Context, alias string) (err error) {
 		return
 	}
 
-	_ = e.cache.DeleteProject(ctx, alias)
+	e.invalidate(ctx, projectInvalidations(alias, true)...)
 
 	return
 }
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+var errIncompleteFile = errors.New("file stream closed before EOF")
+
+// File — поток файла проекта с метаданными для HTTP-ответа.
+type File struct {
+	Body io.ReadCloser
+	// Version — конкретный тег, к которому разрешена запрошенная версия.
+	Version string
+	// StatusCode — 200, либо 206/304, если источник сам ответил на переданные Range и условные заголовки.
+	StatusCode  int
+	ContentType string
+	// ContentLength — размер тела в байтах или -1, если он неизвестен.
+	ContentLength int64
+	ContentRange  string
+	ETag          string
+	LastModified  time.Time
+	// Cached — файл отдан из локального кеша, без обращения к источнику; Body в этом случае поддерживает io.Seeker.
+	Cached bool
+	// Verifying — Body сверяется с контрольной суммой только на EOF: вырезанный из него диапазон остался бы
+	// непроверенным, поэтому такой файл отдаётся целиком, а Range обслуживается уже из кеша.
+	Verifying bool
+}
+
+func newUpstreamFile(resp *http.Response, version string) (file *File) {
+
+	file = &File{
+		Body:          resp.Body,
+		Version:       version,
+		StatusCode:    resp.StatusCode,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		ContentRange:  resp.Header.Get("Content-Range"),
+		ETag:          resp.Header.Get("ETag"),
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		file.LastModified = lastModified
+	}
+	return
+}
+
+// cachingReader дублирует поток из источника в файловый кеш. Запись фиксируется только при чтении до io.EOF,
+// иначе (обрыв, закрытие клиентом) PutFile получает ошибку и частичный файл отбрасывается.
+type cachingReader struct {
+	body     io.ReadCloser
+	pw       *io.PipeWriter
+	done     chan struct{}
+	failed   bool
+	finished bool
+}
+
+func (e *engine) cacheFileStream(ctx context.Context, alias string, version string, filename string, file *File) (stream io.ReadCloser) {
+
+	pr, pw := io.Pipe()
+	info := domain.FileInfo{
+		ContentType: file.ContentType,
+		ETag:        file.ETag,
+		ModTime:     file.LastModified,
+	}
+	r := &cachingReader{
+		body: file.Body,
+		pw:   pw,
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(r.done)
+		err := e.fileCache.PutFile(context.WithoutCancel(ctx), alias, version, filename, info, pr)
+		// разблокирует писателя, если PutFile завершился раньше конца потока
+		_ = pr.CloseWithError(err)
+		if err != nil && !errors.Is(err, errIncompleteFile) {
+			slog.DebugContext(ctx, "Failed to store file in cache",
+				slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+				slog.String(helpers.LogKeyAlias, alias),
+				slog.String(helpers.LogKeyVersion, version),
+				slog.String(helpers.LogKeyFilename, filename),
+				slog.Any(helpers.LogKeyError, err),
+			)
+		}
+	}()
+
+	return r
+}
+
+func (r *cachingReader) Read(p []byte) (n int, err error) {
+
+	n, err = r.body.Read(p)
+	if n > 0 && !r.failed && !r.finished {
+		if _, writeErr := r.pw.Write(p[:n]); writeErr != nil {
+			r.failed = true
+		}
+	}
+	if err == io.EOF && !r.finished {
+		r.finished = true
+		_ = r.pw.Close()
+	}
+	return
+}
+
+func (r *cachingReader) Close() (err error) {
+
+	if !r.finished {
+		r.finished = true
+		_ = r.pw.CloseWithError(errIncompleteFile)
+	}
+	err = r.body.Close()
+	<-r.done
+	return
+}
+
+// fitsFileCache — поместится ли файл известного размера в кеш; поток неизвестной длины не докачивается.
+func fitsFileCache(fileCache fileCache, size int64) (ok bool) {
+
+	if size < 0 {
+		return false
+	}
+	var dependency any = fileCache
+	for dependency != nil {
+		if limit, isLimited := dependency.(fileCacheLimit); isLimited {
+			return limit.Fits(size)
+		}
+		w, isWrapper := dependency.(unwrapper)
+		if !isWrapper {
+			break
+		}
+		dependency = w.unwrap()
+	}
+	return true
+}
+
+// drainingBody при закрытии не обрывает поток, а дочитывает его в фоне: так cachingReader
+// доходит до io.EOF и файл сохраняется в кеш, хотя клиенту нужен был только диапазон.
+type drainingBody struct {
+	body io.ReadCloser
+}
+
+func (b *drainingBody) Read(p []byte) (n int, err error) {
+
+	return b.body.Read(p)
+}
+
+func (b *drainingBody) Close() (err error) {
+
+	go func() {
+		_, _ = io.Copy(io.Discard, b.body)
+		_ = b.body.Close()
+	}()
+	return
+}
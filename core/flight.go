@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// coalesce выполняет fn один раз для всех одновременных вызовов с тем же ключом, остальные получают тот же результат.
+// fn работает с контекстом без отмены: если первый клиент оборвёт запрос, загрузка для остальных продолжится,
+// а каждый вызывающий ждёт результат не дольше своего ctx.
+func coalesce[T any](ctx context.Context, group *singleflight.Group, key string, fn func(ctx context.Context) (T, error)) (result T, shared bool, err error) {
+
+	flightCtx := context.WithoutCancel(ctx)
+	ch := group.DoChan(key, func() (any, error) {
+		return fn(flightCtx)
+	})
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	case res := <-ch:
+		if res.Err != nil {
+			err = res.Err
+			return
+		}
+		result, _ = res.Val.(T)
+		shared = res.Shared
+		return
+	}
+}
+
+// flightKey — ключ операции: имя операции и её параметры через нулевой байт, чтобы части не склеивались.
+func flightKey(op string, parts ...string) (key string) {
+
+	return op + "\x00" + strings.Join(parts, "\x00")
+}
+
+// aliasFlights помнит ключи загрузок, которые сейчас идут для алиаса, чтобы при изменении проекта забыть их разом:
+// запросы после сброса не должны получать результат загрузки, начатой со старыми настройками.
+type aliasFlights struct {
+	mu     sync.Mutex
+	lastID uint64
+	keys   map[string]map[uint64]string
+}
+
+// track регистрирует загрузку ключа key; done снимает регистрацию, когда загрузка закончилась.
+func (f *aliasFlights) track(alias string, key string) (done func()) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.keys == nil {
+		f.keys = make(map[string]map[uint64]string)
+	}
+	if f.keys[alias] == nil {
+		f.keys[alias] = make(map[uint64]string)
+	}
+	f.lastID++
+	id := f.lastID
+	f.keys[alias][id] = key
+
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		delete(f.keys[alias], id)
+		if len(f.keys[alias]) == 0 {
+			delete(f.keys, alias)
+		}
+	}
+}
+
+// forget убирает из group все идущие загрузки алиаса: их результат получат только те, кто уже ждёт.
+func (f *aliasFlights) forget(group *singleflight.Group, alias string) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, key := range f.keys[alias] {
+		group.Forget(key)
+	}
+}
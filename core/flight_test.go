@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/sync/singleflight"
+)
+
+func TestAliasFlightsForget(t *testing.T) {
+
+	var group singleflight.Group
+	var flights aliasFlights
+	key := flightKey("versions", "tool")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	old := make(chan string, 1)
+	go func() {
+		result, _, _ := coalesce(context.Background(), &group, key, func(ctx context.Context) (string, error) {
+			defer flights.track("tool", key)()
+			close(started)
+			<-release
+			return "old", nil
+		})
+		old <- result
+	}()
+	<-started
+
+	flights.forget(&group, "other")
+	flights.forget(&group, "tool")
+
+	result, shared, err := coalesce(context.Background(), &group, key, func(ctx context.Context) (string, error) {
+		return "new", nil
+	})
+	if err != nil || result != "new" || shared {
+		t.Fatalf("expected fresh load after forget, got %q (shared: %v, err: %v)", result, shared, err)
+	}
+
+	close(release)
+	if result := <-old; result != "old" {
+		t.Fatalf("expected waiting caller to keep its load, got %q", result)
+	}
+	flights.mu.Lock()
+	defer flights.mu.Unlock()
+	if len(flights.keys) != 0 {
+		t.Fatalf("expected finished loads to be untracked, got %v", flights.keys)
+	}
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/resilience"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+	// HealthStatusDegraded — зависимость частично недоступна, но реплика может обслуживать запросы.
+	HealthStatusDegraded = "degraded"
+)
+
+// HealthCheck — результат проверки одной зависимости.
+type HealthCheck struct {
+	Name    string
+	Status  string
+	Latency time.Duration
+	Error   string
+}
+
+// pinger — необязательное расширение хранилища, кеша и источника; зависимости без Ping в проверку не попадают.
+type pinger interface {
+	Ping(ctx context.Context) (err error)
+}
+
+// breakerReporter — необязательное расширение источника с circuit breaker (см. пакет resilience).
+type breakerReporter interface {
+	CircuitBreakers() (statuses []resilience.BreakerStatus)
+}
+
+// unwrapper реализуют обёртки движка (трассировка), чтобы проверка нашла Ping исходной зависимости.
+type unwrapper interface {
+	unwrap() (next any)
+}
+
+// HealthCheckTimeout ограничивает время проверки одной зависимости (по умолчанию 2 секунды).
+func HealthCheckTimeout(timeout time.Duration) (opt EngineOption) {
+	return func(e *engine) {
+		e.healthCheckTimeout = timeout
+	}
+}
+
+// CheckReadiness параллельно проверяет хранилище, кеш и, если withSources, зарегистрированные источники,
+// и добавляет состояние circuit breaker источников. ready — ни одна проверка не в состоянии down.
+func (e *engine) CheckReadiness(ctx context.Context, withSources bool) (checks []HealthCheck, ready bool) {
+
+	targets := make(map[string]pinger)
+	if p, ok := asPinger(e.storage); ok {
+		targets["storage"] = p
+	}
+	if p, ok := asPinger(e.cache); ok {
+		targets["cache"] = p
+	}
+	e.sourcesMu.RLock()
+	for name, src := range e.sources {
+		if p, ok := asPinger(src); ok && withSources {
+			targets["source:"+name] = p
+		}
+		if reporter, ok := src.(breakerReporter); ok {
+			checks = append(checks, breakerChecks(name, reporter, withSources)...)
+		}
+	}
+	e.sourcesMu.RUnlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check := e.ping(ctx, name, target)
+			mu.Lock()
+			checks = append(checks, check)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+
+	ready = true
+	for _, check := range checks {
+		if check.Status == HealthStatusDown {
+			ready = false
+		}
+	}
+	return
+}
+
+// breakerChecks показывает разомкнутые breaker источника. Без withSources они не снимают готовность:
+// недоступность источника не должна выводить реплику из балансировки.
+func breakerChecks(source string, reporter breakerReporter, withSources bool) (checks []HealthCheck) {
+
+	for _, status := range reporter.CircuitBreakers() {
+		check := HealthCheck{Name: "circuit:" + source + ":" + status.Host, Status: HealthStatusUp}
+		switch status.State {
+		case resilience.StateOpen:
+			check.Status = HealthStatusDegraded
+			if withSources {
+				check.Status = HealthStatusDown
+			}
+			check.Error = fmt.Sprintf("circuit open after %d failures until %s", status.Failures, status.OpenUntil.Format(time.RFC3339))
+		case resilience.StateHalfOpen:
+			check.Status = HealthStatusDegraded
+			check.Error = "circuit half-open, probing"
+		}
+		checks = append(checks, check)
+	}
+	return
+}
+
+func (e *engine) ping(ctx context.Context, name string, target pinger) (check HealthCheck) {
+
+	ctx, cancel := context.WithTimeout(ctx, e.healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := target.Ping(ctx)
+	check = HealthCheck{Name: name, Status: HealthStatusUp, Latency: time.Since(start)}
+	if err != nil {
+		check.Status = HealthStatusDown
+		check.Error = err.Error()
+	}
+	return
+}
+
+func asPinger(dependency any) (p pinger, ok bool) {
+
+	for dependency != nil {
+		if p, ok = dependency.(pinger); ok {
+			return
+		}
+		w, isWrapper := dependency.(unwrapper)
+		if !isWrapper {
+			return
+		}
+		dependency = w.unwrap()
+	}
+	return
+}
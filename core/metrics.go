@@ -0,0 +1,52 @@
+package core
+
+import (
+	"time"
+)
+
+const (
+	cacheKindProject   = "project"
+	cacheKindVersions  = "versions"
+	cacheKindManifest  = "manifest"
+	cacheKindAggregate = "aggregate_manifest"
+	cacheKindNotFound  = "not_found"
+	cacheKindFile      = "file"
+)
+
+const (
+	upstreamGetVersions = "get_versions"
+	upstreamGetManifest = "get_manifest"
+	upstreamGetFile     = "get_file"
+)
+
+// metrics принимает наблюдения движка: обращения к источникам и результаты поиска в кешах.
+type metrics interface {
+	ObserveUpstream(source string, operation string, duration time.Duration, err error)
+	ObserveCache(kind string, hit bool)
+}
+
+// Metrics включает сбор метрик движка (например, metrics.Metrics).
+func Metrics(m metrics) (opt EngineOption) {
+	return func(e *engine) {
+		e.observer.metrics = m
+	}
+}
+
+// observer избавляет места учёта от проверки metrics на nil.
+type observer struct {
+	metrics metrics
+}
+
+func (o observer) cache(kind string, hit bool) {
+
+	if o.metrics != nil {
+		o.metrics.ObserveCache(kind, hit)
+	}
+}
+
+func (o observer) upstream(source string, operation string, start time.Time, err error) {
+
+	if o.metrics != nil {
+		o.metrics.ObserveUpstream(source, operation, time.Since(start), err)
+	}
+}
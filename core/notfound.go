@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+const defaultNotFoundTTL = time.Minute
+
+const notFoundProjectKey = "project"
+
+func notFoundManifestKey(version string) (key string) {
+
+	return "manifest:" + version
+}
+
+func notFoundFileKey(version string, filename string) (key string) {
+
+	return "file:" + version + "/" + filename
+}
+
+// NotFoundTTL задаёт срок отрицательного кеша для отсутствующих проектов, манифестов версий и файлов
+// (по умолчанию минута); 0 отключает его. Отметки проекта снимаются при его создании или изменении
+// и при обновлении списка версий из источника.
+func NotFoundTTL(ttl time.Duration) (opt EngineOption) {
+	return func(e *engine) {
+		e.notFoundTTL = ttl
+	}
+}
+
+func isNotFoundCached(ctx context.Context, c cache, obs observer, ttl time.Duration, alias string, key string) (notFound bool) {
+
+	if c == nil || ttl <= 0 {
+		return
+	}
+	notFound, _ = c.GetNotFound(ctx, alias, key)
+	obs.cache(cacheKindNotFound, notFound)
+	return
+}
+
+func cacheNotFound(ctx context.Context, c cache, ttl time.Duration, alias string, key string) {
+
+	if c == nil || ttl <= 0 {
+		return
+	}
+	_ = c.SetNotFound(ctx, alias, key, ttl)
+}
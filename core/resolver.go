@@ -4,37 +4,71 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
 	"github.com/seniorGolang/tg-proxy/helpers"
 	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/tracing"
 )
 
 const projectTTL = 1 * time.Hour
 
 type resolver struct {
-	storage   storage
-	encryptor encryptor
-	cache     cache
+	storage       storage
+	encryptor     encryptor
+	cache         cache
+	notFoundTTL   time.Duration
+	observer      observer
+	tracer        trace.Tracer
+	flight        singleflight.Group
+	staleFailures sync.Map
+}
+
+type projectLookup struct {
+	project domain.Project
+	found   bool
 }
 
-func newResolver(stor storage, enc encryptor, c cache) (res *resolver) {
+func newResolver(stor storage, enc encryptor, c cache, notFoundTTL time.Duration, obs observer, tracer trace.Tracer) (res *resolver) {
 
 	return &resolver{
-		storage:   stor,
-		encryptor: enc,
-		cache:     c,
+		storage:     stor,
+		encryptor:   enc,
+		cache:       c,
+		notFoundTTL: notFoundTTL,
+		observer:    obs,
+		tracer:      tracer,
 	}
 }
 
 func (r *resolver) ResolveProject(ctx context.Context, alias string) (project domain.Project, found bool, err error) {
 
+	ctx, span := r.tracer.Start(ctx, "resolver.ResolveProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	key := flightKey("project", alias)
+	load := func(ctx context.Context) (projectLookup, error) {
+		project, found, err := r.loadProject(ctx, alias)
+		return projectLookup{project: project, found: found}, err
+	}
+
 	if r.cache != nil {
-		if project, found, _ = r.cache.GetProject(ctx, alias); found {
-			slog.Debug("Project found in cache",
+		var stale bool
+		project, found, stale, _ = r.cache.GetProject(ctx, alias)
+		r.observer.cache(cacheKindProject, found)
+		if found {
+			slog.DebugContext(ctx, "Project found in cache",
 				slog.String(helpers.LogKeyAction, helpers.ActionResolveProject),
 				slog.String(helpers.LogKeyAlias, alias),
+				slog.Bool("stale", stale),
 			)
+			if stale && r.storage != nil {
+				revalidate(ctx, &r.flight, &r.staleFailures, key, load)
+			}
 			return
 		}
 	}
@@ -43,8 +77,24 @@ func (r *resolver) ResolveProject(ctx context.Context, alias string) (project do
 		return
 	}
 
+	if isNotFoundCached(ctx, r.cache, r.observer, r.notFoundTTL, alias, notFoundProjectKey) {
+		return
+	}
+
+	var lookup projectLookup
+	if lookup, _, err = coalesce(ctx, &r.flight, key, load); err != nil {
+		return
+	}
+	r.staleFailures.Delete(key)
+
+	return lookup.project, lookup.found, nil
+}
+
+// loadProject читает проект из хранилища, расшифровывает токен и кладёт результат в кеш.
+func (r *resolver) loadProject(ctx context.Context, alias string) (project domain.Project, found bool, err error) {
+
 	if project, found, err = r.storage.GetProject(ctx, alias); err != nil {
-		slog.Debug("Failed to get project from storage",
+		slog.DebugContext(ctx, "Failed to get project from storage",
 			slog.String(helpers.LogKeyAction, helpers.ActionResolveProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Any(helpers.LogKeyError, err),
@@ -52,13 +102,17 @@ func (r *resolver) ResolveProject(ctx context.Context, alias string) (project do
 		return
 	}
 	if !found {
+		cacheNotFound(ctx, r.cache, r.notFoundTTL, alias, notFoundProjectKey)
 		return
 	}
 
 	if project.EncryptedToken != "" && r.encryptor != nil {
 		var token string
-		if token, err = r.encryptor.DecryptString(project.EncryptedToken); err != nil {
-			slog.Debug("Failed to decrypt token",
+		_, decryptSpan := r.tracer.Start(ctx, "encryptor.DecryptString")
+		token, err = r.encryptor.DecryptString(project.EncryptedToken)
+		tracing.End(decryptSpan, err)
+		if err != nil {
+			slog.DebugContext(ctx, "Failed to decrypt token",
 				slog.String(helpers.LogKeyAction, helpers.ActionResolveProject),
 				slog.String(helpers.LogKeyAlias, alias),
 				slog.Any(helpers.LogKeyError, err),
@@ -78,10 +132,14 @@ func (r *resolver) ResolveProject(ctx context.Context, alias string) (project do
 	return
 }
 
+// InvalidateCache сбрасывает проект в кеше (а с ним версии и манифесты, см. cache.DeleteProject) и отметки отсутствия.
 func (r *resolver) InvalidateCache(ctx context.Context, alias string) (err error) {
 
+	// загрузка, начатая до изменения проекта, не должна отдаваться новым запросам
+	r.flight.Forget(flightKey("project", alias))
 	if r.cache != nil {
 		_ = r.cache.DeleteProject(ctx, alias)
+		_ = r.cache.DeleteNotFound(ctx, alias)
 	}
 	return
 }
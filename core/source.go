@@ -16,3 +16,21 @@ type Source interface {
 	GetFileResponse(ctx context.Context, project domain.Project, version string, filename string) (resp *http.Response, err error)
 	GetVersions(ctx context.Context, project domain.Project) (versions []string, err error)
 }
+
+// FileRequestSource — необязательное расширение Source: передаёт источнику Range и условные заголовки клиента
+// и возвращает ответы 206 Partial Content и 304 Not Modified без преобразования в ошибку.
+type FileRequestSource interface {
+	GetFileResponseWithHeader(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error)
+}
+
+// HeadFileSource — необязательное расширение Source: запрос HEAD к файлу, чтобы ответить на HEAD клиента
+// без скачивания тела. Условные заголовки передаются так же, как в FileRequestSource.
+type HeadFileSource interface {
+	HeadFileResponse(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error)
+}
+
+// SettingsValidator — необязательное расширение Source: проверяет настройки проекта (domain.Project.Settings),
+// чтобы ошибка в них обнаруживалась при сохранении проекта, а не при первом запросе к источнику.
+type SettingsValidator interface {
+	ValidateSettings(settings map[string]string) (err error)
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+)
+
+const (
+	warningStale              = `110 - "Response is Stale"`
+	warningRevalidationFailed = `111 - "Revalidation Failed"`
+)
+
+type staleReportKey struct{}
+
+// StaleReport отмечает, что при обработке запроса из кеша были отданы устаревшие данные.
+type StaleReport struct {
+	mu     sync.Mutex
+	stale  bool
+	failed bool
+}
+
+// WithStaleReport добавляет в контекст отчёт об устаревших данных; по нему HTTP-слой выставляет заголовок Warning.
+func WithStaleReport(ctx context.Context) (reportCtx context.Context, report *StaleReport) {
+
+	report = &StaleReport{}
+	return context.WithValue(ctx, staleReportKey{}, report), report
+}
+
+// Warning — значение заголовка Warning: 110, если данные устарели, и 111, если обновить их из источника не удалось.
+func (r *StaleReport) Warning() (warning string) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.failed:
+		return warningRevalidationFailed
+	case r.stale:
+		return warningStale
+	default:
+		return ""
+	}
+}
+
+func markStale(ctx context.Context, revalidationFailed bool) {
+
+	report, ok := ctx.Value(staleReportKey{}).(*StaleReport)
+	if !ok {
+		return
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	report.stale = true
+	report.failed = report.failed || revalidationFailed
+}
+
+// revalidate помечает ответ устаревшим и обновляет запись в фоне под тем же ключом singleflight, что и обычная загрузка.
+// Ошибка обновления запоминается в failures: пока запись не обновится, ответы помечаются как Warning 111.
+func revalidate[T any](ctx context.Context, group *singleflight.Group, failures *sync.Map, key string, fn func(ctx context.Context) (T, error)) {
+
+	_, failed := failures.Load(key)
+	markStale(ctx, failed)
+
+	go func() {
+		if _, _, err := coalesce(context.WithoutCancel(ctx), group, key, fn); err != nil {
+			failures.Store(key, struct{}{})
+			slog.WarnContext(ctx, "Failed to revalidate stale cache entry, serving last good value",
+				slog.String("cache_key", strings.ReplaceAll(key, "\x00", "/")),
+				slog.Any(helpers.LogKeyError, err),
+			)
+			return
+		}
+		failures.Delete(key)
+	}()
+}
@@ -0,0 +1,314 @@
+package core
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/tracing"
+)
+
+const (
+	attrAlias    = attribute.Key("tgproxy.alias")
+	attrVersion  = attribute.Key("tgproxy.version")
+	attrFilename = attribute.Key("tgproxy.filename")
+	attrSource   = attribute.Key("tgproxy.source")
+	attrFound    = attribute.Key("tgproxy.found")
+	attrStale    = attribute.Key("tgproxy.stale")
+)
+
+// TracerProvider включает трассировку операций движка, резолвера, кешей, хранилища и обращений к источникам.
+// Без неё трассировщик no-op.
+func TracerProvider(tp trace.TracerProvider) (opt EngineOption) {
+	return func(e *engine) {
+		e.tracerProvider = tp
+	}
+}
+
+// traceDependencies оборачивает кеши и хранилище, чтобы каждый их вызов попадал в трассу отдельным спаном.
+func (e *engine) traceDependencies() {
+
+	if e.tracerProvider == nil {
+		return
+	}
+	if e.cache != nil {
+		e.cache = &tracedCache{next: e.cache, tracer: e.tracer}
+	}
+	if e.storage != nil {
+		e.storage = &tracedStorage{next: e.storage, tracer: e.tracer}
+	}
+	if e.fileCache != nil {
+		e.fileCache = &tracedFileCache{next: e.fileCache, tracer: e.tracer}
+	}
+}
+
+// startUpstream открывает спан обращения к источнику; done завершает его и учитывает вызов в метриках.
+func (e *engine) startUpstream(ctx context.Context, source string, operation string) (sourceCtx context.Context, done func(err error)) {
+
+	start := time.Now()
+	sourceCtx, span := e.tracer.Start(ctx, "source."+operation, trace.WithAttributes(attrSource.String(source)))
+	return sourceCtx, func(err error) {
+		e.observer.upstream(source, operation, start, err)
+		tracing.End(span, err)
+	}
+}
+
+func endSpan(span trace.Span, err *error) {
+
+	tracing.End(span, *err)
+}
+
+type tracedCache struct {
+	next   cache
+	tracer trace.Tracer
+}
+
+func (c *tracedCache) unwrap() (next any) {
+
+	return c.next
+}
+
+func (c *tracedCache) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, stale bool, err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.GetProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	project, found, stale, err = c.next.GetProject(ctx, alias)
+	span.SetAttributes(attrFound.Bool(found), attrStale.Bool(stale))
+	return
+}
+
+func (c *tracedCache) SetProject(ctx context.Context, alias string, project domain.Project, ttl time.Duration) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.SetProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return c.next.SetProject(ctx, alias, project, ttl)
+}
+
+func (c *tracedCache) DeleteProject(ctx context.Context, alias string) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.DeleteProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return c.next.DeleteProject(ctx, alias)
+}
+
+func (c *tracedCache) GetVersions(ctx context.Context, alias string) (versions []string, found bool, stale bool, err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.GetVersions", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	versions, found, stale, err = c.next.GetVersions(ctx, alias)
+	span.SetAttributes(attrFound.Bool(found), attrStale.Bool(stale))
+	return
+}
+
+func (c *tracedCache) SetVersions(ctx context.Context, alias string, versions []string, ttl time.Duration) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.SetVersions", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return c.next.SetVersions(ctx, alias, versions, ttl)
+}
+
+func (c *tracedCache) GetAggregateManifest(ctx context.Context) (manifest []byte, found bool, err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.GetAggregateManifest")
+	defer endSpan(span, &err)
+
+	manifest, found, err = c.next.GetAggregateManifest(ctx)
+	span.SetAttributes(attrFound.Bool(found))
+	return
+}
+
+func (c *tracedCache) SetAggregateManifest(ctx context.Context, manifest []byte, ttl time.Duration) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.SetAggregateManifest")
+	defer endSpan(span, &err)
+
+	return c.next.SetAggregateManifest(ctx, manifest, ttl)
+}
+
+func (c *tracedCache) DeleteAggregateManifest(ctx context.Context) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.DeleteAggregateManifest")
+	defer endSpan(span, &err)
+
+	return c.next.DeleteAggregateManifest(ctx)
+}
+
+func (c *tracedCache) GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, found bool, stale bool, err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.GetManifest", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version)))
+	defer endSpan(span, &err)
+
+	manifest, found, stale, err = c.next.GetManifest(ctx, alias, version, baseURL)
+	span.SetAttributes(attrFound.Bool(found), attrStale.Bool(stale))
+	return
+}
+
+func (c *tracedCache) SetManifest(ctx context.Context, alias string, version string, baseURL string, manifest []byte, ttl time.Duration) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.SetManifest", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version)))
+	defer endSpan(span, &err)
+
+	return c.next.SetManifest(ctx, alias, version, baseURL, manifest, ttl)
+}
+
+func (c *tracedCache) GetNotFound(ctx context.Context, alias string, key string) (found bool, err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.GetNotFound", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	found, err = c.next.GetNotFound(ctx, alias, key)
+	span.SetAttributes(attrFound.Bool(found))
+	return
+}
+
+func (c *tracedCache) SetNotFound(ctx context.Context, alias string, key string, ttl time.Duration) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.SetNotFound", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return c.next.SetNotFound(ctx, alias, key, ttl)
+}
+
+func (c *tracedCache) DeleteNotFound(ctx context.Context, alias string) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.DeleteNotFound", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return c.next.DeleteNotFound(ctx, alias)
+}
+
+func (c *tracedCache) Clear(ctx context.Context) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "cache.Clear")
+	defer endSpan(span, &err)
+
+	return c.next.Clear(ctx)
+}
+
+type tracedStorage struct {
+	next   storage
+	tracer trace.Tracer
+}
+
+func (s *tracedStorage) unwrap() (next any) {
+
+	return s.next
+}
+
+func (s *tracedStorage) GetProject(ctx context.Context, alias string) (project domain.Project, found bool, err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.GetProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	project, found, err = s.next.GetProject(ctx, alias)
+	span.SetAttributes(attrFound.Bool(found))
+	return
+}
+
+func (s *tracedStorage) GetProjectByID(ctx context.Context, id uuid.UUID) (project domain.Project, found bool, err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.GetProjectByID")
+	defer endSpan(span, &err)
+
+	project, found, err = s.next.GetProjectByID(ctx, id)
+	span.SetAttributes(attrFound.Bool(found))
+	return
+}
+
+func (s *tracedStorage) GetProjectByRepoURL(ctx context.Context, repoURL string) (project domain.Project, found bool, err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.GetProjectByRepoURL", trace.WithAttributes(attribute.String("tgproxy.repo_url", repoURL)))
+	defer endSpan(span, &err)
+
+	project, found, err = s.next.GetProjectByRepoURL(ctx, repoURL)
+	span.SetAttributes(attrFound.Bool(found))
+	return
+}
+
+func (s *tracedStorage) CreateProject(ctx context.Context, project domain.Project) (id uuid.UUID, err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.CreateProject", trace.WithAttributes(attrAlias.String(project.Alias)))
+	defer endSpan(span, &err)
+
+	return s.next.CreateProject(ctx, project)
+}
+
+func (s *tracedStorage) UpdateProject(ctx context.Context, alias string, project domain.Project) (err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.UpdateProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return s.next.UpdateProject(ctx, alias, project)
+}
+
+func (s *tracedStorage) DeleteProject(ctx context.Context, alias string) (err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.DeleteProject", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return s.next.DeleteProject(ctx, alias)
+}
+
+func (s *tracedStorage) ListProjects(ctx context.Context, limit int, offset int) (projects []domain.Project, total int64, err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.ListProjects")
+	defer endSpan(span, &err)
+
+	return s.next.ListProjects(ctx, limit, offset)
+}
+
+func (s *tracedStorage) GetCatalogVersion(ctx context.Context) (version string, err error) {
+
+	ctx, span := s.tracer.Start(ctx, "storage.GetCatalogVersion")
+	defer endSpan(span, &err)
+
+	return s.next.GetCatalogVersion(ctx)
+}
+
+type tracedFileCache struct {
+	next   fileCache
+	tracer trace.Tracer
+}
+
+func (c *tracedFileCache) unwrap() (next any) {
+
+	return c.next
+}
+
+func (c *tracedFileCache) OpenFile(ctx context.Context, alias string, version string, filename string) (body io.ReadSeekCloser, info domain.FileInfo, found bool, err error) {
+
+	ctx, span := c.tracer.Start(ctx, "filecache.OpenFile", trace.WithAttributes(
+		attrAlias.String(alias), attrVersion.String(version), attrFilename.String(filename)))
+	defer endSpan(span, &err)
+
+	body, info, found, err = c.next.OpenFile(ctx, alias, version, filename)
+	span.SetAttributes(attrFound.Bool(found))
+	return
+}
+
+func (c *tracedFileCache) PutFile(ctx context.Context, alias string, version string, filename string, info domain.FileInfo, body io.Reader) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "filecache.PutFile", trace.WithAttributes(
+		attrAlias.String(alias), attrVersion.String(version), attrFilename.String(filename)))
+	defer endSpan(span, &err)
+
+	return c.next.PutFile(ctx, alias, version, filename, info, body)
+}
+
+func (c *tracedFileCache) DeleteFiles(ctx context.Context, alias string) (err error) {
+
+	ctx, span := c.tracer.Start(ctx, "filecache.DeleteFiles", trace.WithAttributes(attrAlias.String(alias)))
+	defer endSpan(span, &err)
+
+	return c.next.DeleteFiles(ctx, alias)
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"strings"
 
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v3"
 
 	"github.com/seniorGolang/tg-proxy/errs"
@@ -23,11 +24,13 @@ type Dependency struct {
 
 type transformer struct {
 	storage storage
+	tracer  trace.Tracer
 }
 
-func newTransformer(stor storage) (t *transformer) {
+func newTransformer(stor storage, tracer trace.Tracer) (t *transformer) {
 	return &transformer{
 		storage: stor,
+		tracer:  tracer,
 	}
 }
 
@@ -95,6 +98,9 @@ func (t *transformer) Transform(ctx context.Context, manifest *model.Manifest, a
 // ReplaceManifestURLs заменяет URL в манифесте на прокси (модифицирует manifest на месте).
 func (t *transformer) ReplaceManifestURLs(ctx context.Context, manifest *model.Manifest, alias string, version string, baseURL string, sourceDomain string, resolver Source) (err error) {
 
+	ctx, span := t.tracer.Start(ctx, "transformer.ReplaceManifestURLs", trace.WithAttributes(attrAlias.String(alias), attrVersion.String(version)))
+	defer endSpan(span, &err)
+
 	return t.replaceManifestURLs(ctx, manifest, alias, version, baseURL, sourceDomain, resolver)
 }
 
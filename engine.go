@@ -2,7 +2,7 @@ package tgproxy
 
 import (
 	"context"
-	"io"
+	"net/http"
 
 	"github.com/google/uuid"
 
@@ -17,8 +17,10 @@ type engine interface {
 	GetManifest(ctx context.Context, alias string, version string, baseURL string) (manifest []byte, err error)
 	GetManifestData(ctx context.Context, alias string, version string, baseURL string) (m *model.Manifest, err error)
 	GetManifestAggregated(ctx context.Context, alias string, version string, baseURL string) (out *model.ManifestAggregatedResponse, err error)
-	GetFile(ctx context.Context, alias string, version string, filename string) (stream io.ReadCloser, err error)
+	GetFile(ctx context.Context, alias string, version string, filename string, header http.Header) (file *core.File, err error)
+	HeadFile(ctx context.Context, alias string, version string, filename string, header http.Header) (file *core.File, err error)
 	GetVersions(ctx context.Context, alias string) (versions []string, err error)
+	ResolveVersion(ctx context.Context, alias string, version string) (resolved string, err error)
 	GetSource(name string) (src core.Source, err error)
 	CreateProject(ctx context.Context, project domain.Project) (id uuid.UUID, err error)
 	GetProject(ctx context.Context, alias string) (project domain.Project, found bool, err error)
@@ -26,4 +28,5 @@ type engine interface {
 	UpdateProject(ctx context.Context, alias string, project domain.Project) (err error)
 	DeleteProject(ctx context.Context, alias string) (err error)
 	ListProjects(ctx context.Context, limit int, offset int) (projects []domain.Project, total int64, err error)
+	CheckReadiness(ctx context.Context, withSources bool) (checks []core.HealthCheck, ready bool)
 }
@@ -0,0 +1,75 @@
+package tgproxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/dto"
+)
+
+const (
+	contentTypeProblem = "application/problem+json"
+	headerRequestID    = "X-Request-ID"
+)
+
+// Коды ошибок, которые не выводятся из сентинел-ошибок errs.
+const (
+	codeInvalidRequestBody = "invalid_request_body"
+	codeValidationFailed   = "validation_failed"
+	codeInvalidAlias       = "invalid_alias"
+)
+
+// newErrorResponse собирает тело ошибки, общее для net/http и Fiber.
+func newErrorResponse(statusCode int, code string, detail string, instance string, requestID string) (resp dto.ErrorResponse) {
+
+	return dto.ErrorResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    detail,
+		Instance:  instance,
+		Code:      code,
+		RequestID: requestID,
+	}
+}
+
+// writeErrorNetHTTP отвечает ошибкой, код и сообщение которой выводятся из err.
+func writeErrorNetHTTP(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+
+	if delay, ok := helpers.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", retryAfterSeconds(delay))
+	}
+	writeProblemNetHTTP(w, r, statusCode, helpers.GetErrorCode(err), helpers.GetErrorMessage(err))
+}
+
+func writeProblemNetHTTP(w http.ResponseWriter, r *http.Request, statusCode int, code string, detail string) {
+
+	w.Header().Set("Content-Type", contentTypeProblem)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(newErrorResponse(statusCode, code, detail, r.URL.Path, helpers.RequestIDFromContext(r.Context())))
+}
+
+func writeErrorFiber(c *fiber.Ctx, statusCode int, err error) (sendErr error) {
+
+	if delay, ok := helpers.RetryAfter(err); ok {
+		c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(delay))
+	}
+	return writeProblemFiber(c, statusCode, helpers.GetErrorCode(err), helpers.GetErrorMessage(err))
+}
+
+func writeProblemFiber(c *fiber.Ctx, statusCode int, code string, detail string) (err error) {
+
+	return c.Status(statusCode).JSON(newErrorResponse(statusCode, code, detail, c.Path(), helpers.RequestIDFromContext(c.UserContext())), contentTypeProblem)
+}
+
+// retryAfterSeconds округляет паузу вверх до целых секунд, как требует заголовок Retry-After.
+func retryAfterSeconds(delay time.Duration) (value string) {
+
+	return strconv.FormatInt(int64((delay+time.Second-1)/time.Second), 10)
+}
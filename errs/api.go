@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrGitLabAPI = errors.New("gitlab api error")
 	ErrGitHubAPI = errors.New("github api error")
+	ErrGiteaAPI  = errors.New("gitea api error")
 )
@@ -3,5 +3,7 @@ package errs
 import "errors"
 
 var (
-	ErrFileNotFound = errors.New("file not found")
+	ErrFileNotFound        = errors.New("file not found")
+	ErrChecksumMismatch    = errors.New("checksum mismatch")
+	ErrUnsupportedChecksum = errors.New("unsupported checksum")
 )
@@ -0,0 +1,34 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrRateLimited = errors.New("source rate limit exceeded")
+
+// RateLimitError — источник отказал из-за исчерпанного лимита запросов; Reset — когда лимит восстановится.
+type RateLimitError struct {
+	Source string
+	Reset  time.Time
+}
+
+func (e *RateLimitError) Error() (message string) {
+
+	return fmt.Sprintf("%s: %s, resets at %s", ErrRateLimited, e.Source, e.Reset.UTC().Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() (err error) {
+
+	return ErrRateLimited
+}
+
+// RetryAfter — сколько клиенту стоит подождать до повтора (не меньше секунды).
+func (e *RateLimitError) RetryAfter() (delay time.Duration) {
+
+	if delay = time.Until(e.Reset); delay < time.Second {
+		delay = time.Second
+	}
+	return
+}
@@ -7,4 +7,6 @@ var (
 	ErrSourceAlreadyRegistered = errors.New("source already registered")
 	ErrInvalidSourceType       = errors.New("invalid source type")
 	ErrRepoURLSourceMismatch   = errors.New("repo_url must be on the same domain and scheme as the source")
+	ErrSourceUnavailable       = errors.New("source temporarily unavailable")
+	ErrInvalidSourceSettings   = errors.New("invalid source settings")
 )
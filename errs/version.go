@@ -3,6 +3,8 @@ package errs
 import "errors"
 
 var (
-	ErrVersionNotFound = errors.New("version not found")
-	ErrVersionMismatch = errors.New("version mismatch")
+	ErrVersionNotFound          = errors.New("version not found")
+	ErrVersionMismatch          = errors.New("version mismatch")
+	ErrInvalidVersionConstraint = errors.New("invalid version constraint")
+	ErrInvalidPrerelease        = errors.New("prerelease must be a boolean")
 )
@@ -1,18 +1,15 @@
 package tgproxy
 
 import (
-	"io"
 	"log/slog"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
-	"github.com/seniorGolang/tg-proxy/core"
+	"github.com/seniorGolang/tg-proxy/errs"
 	"github.com/seniorGolang/tg-proxy/helpers"
-	"github.com/seniorGolang/tg-proxy/model/domain"
 	"github.com/seniorGolang/tg-proxy/model/dto"
 )
 
@@ -23,7 +20,7 @@ func (p *Proxy) SetPublicRoutesFiber(app *fiber.App, prefix string) {
 		base = "/"
 	}
 	p.publicPrefix = base
-	group := app.Group(prefix, p.publicFiberAuthMiddleware)
+	group := app.Group(prefix, p.requestFiberMiddleware, p.metricsFiberMiddleware, p.tracingFiberMiddleware, p.publicFiberAuthMiddleware, p.staleWarningFiberMiddleware)
 	group.Get("/", p.handleGetAggregateManifestFiber)
 	group.Get("/manifest.yml", p.handleGetAggregateManifestFiber)
 	group.Get("/versions", p.handleGetCatalogVersionFiber)
@@ -35,7 +32,7 @@ func (p *Proxy) SetPublicRoutesFiber(app *fiber.App, prefix string) {
 
 func (p *Proxy) SetAdminRoutesFiber(app *fiber.App, prefix string) {
 
-	group := app.Group(prefix, p.adminFiberAuthMiddleware)
+	group := app.Group(prefix, p.requestFiberMiddleware, p.metricsFiberMiddleware, p.tracingFiberMiddleware, p.adminFiberAuthMiddleware)
 	group.Get("/projects", p.handleListProjectsFiber)
 	group.Post("/projects", p.handleCreateProjectFiber)
 	group.Get("/projects/:alias", p.handleGetProjectFiber)
@@ -51,9 +48,9 @@ func (p *Proxy) handleGetManifestFiber(c *fiber.Ctx) (err error) {
 	alias := c.Params("alias")
 	version := c.Params("version")
 
-	manifest, statusCode, err := p.handleGetManifest(c.Context(), alias, version)
+	manifest, resolved, etag, statusCode, err := p.handleGetManifest(c.UserContext(), alias, version, c.Get(fiber.HeaderIfNoneMatch))
 	if err != nil {
-		slog.Error("Failed to get manifest",
+		slog.ErrorContext(c.UserContext(), "Failed to get manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -63,15 +60,14 @@ func (p *Proxy) handleGetManifestFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Manifest request completed",
+	slog.InfoContext(c.UserContext(), "Manifest request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyResolvedVersion, resolved),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
 		slog.String(helpers.LogKeyMethod, c.Method()),
 		slog.String(helpers.LogKeyPath, c.Path()),
@@ -80,6 +76,13 @@ func (p *Proxy) handleGetManifestFiber(c *fiber.Ctx) (err error) {
 	)
 
 	c.Set("Content-Type", "application/x-yaml")
+	c.Set(headerResolvedVersion, resolved)
+	c.Set("ETag", etag)
+	c.Set("Cache-Control", manifestCacheControl(version, resolved))
+	if statusCode == fiber.StatusNotModified {
+		c.Status(statusCode)
+		return nil
+	}
 	return c.Status(statusCode).Send(manifest)
 }
 
@@ -87,9 +90,9 @@ func (p *Proxy) handleGetAggregateManifestFiber(c *fiber.Ctx) (err error) {
 
 	startTime := time.Now()
 
-	manifest, statusCode, err := p.handleGetAggregateManifest(c.Context())
+	manifest, etag, statusCode, err := p.handleGetAggregateManifest(c.UserContext(), c.Get(fiber.HeaderIfNoneMatch))
 	if err != nil {
-		slog.Error("Failed to get aggregate manifest",
+		slog.ErrorContext(c.UserContext(), "Failed to get aggregate manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, c.Method()),
@@ -97,12 +100,10 @@ func (p *Proxy) handleGetAggregateManifestFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Aggregate manifest request completed",
+	slog.InfoContext(c.UserContext(), "Aggregate manifest request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
 		slog.String(helpers.LogKeyMethod, c.Method()),
@@ -112,6 +113,12 @@ func (p *Proxy) handleGetAggregateManifestFiber(c *fiber.Ctx) (err error) {
 	)
 
 	c.Set("Content-Type", "application/x-yaml")
+	c.Set("ETag", etag)
+	c.Set("Cache-Control", cacheControlAggregate)
+	if statusCode == fiber.StatusNotModified {
+		c.Status(statusCode)
+		return nil
+	}
 	return c.Status(statusCode).Send(manifest)
 }
 
@@ -120,9 +127,9 @@ func (p *Proxy) handleGetAggregateManifestAtVersionFiber(c *fiber.Ctx) (err erro
 	startTime := time.Now()
 	requestedVersion := c.Params("version")
 
-	currentVersion, statusCode, err := p.handleGetCatalogVersion(c.Context())
+	currentVersion, statusCode, err := p.handleGetCatalogVersion(c.UserContext())
 	if err != nil {
-		slog.Error("Failed to get catalog version",
+		slog.ErrorContext(c.UserContext(), "Failed to get catalog version",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, c.Method()),
@@ -130,17 +137,15 @@ func (p *Proxy) handleGetAggregateManifestAtVersionFiber(c *fiber.Ctx) (err erro
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 	if requestedVersion != currentVersion {
-		return c.Status(fiber.StatusNotFound).SendString("Not found")
+		return writeErrorFiber(c, fiber.StatusNotFound, errs.ErrVersionNotFound)
 	}
 
-	manifest, statusCode, err := p.handleGetAggregateManifest(c.Context())
+	manifest, etag, statusCode, err := p.handleGetAggregateManifest(c.UserContext(), c.Get(fiber.HeaderIfNoneMatch))
 	if err != nil {
-		slog.Error("Failed to get aggregate manifest",
+		slog.ErrorContext(c.UserContext(), "Failed to get aggregate manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, c.Method()),
@@ -148,12 +153,10 @@ func (p *Proxy) handleGetAggregateManifestAtVersionFiber(c *fiber.Ctx) (err erro
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Aggregate manifest request completed",
+	slog.InfoContext(c.UserContext(), "Aggregate manifest request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 		slog.String(helpers.LogKeyVersion, currentVersion),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -164,6 +167,12 @@ func (p *Proxy) handleGetAggregateManifestAtVersionFiber(c *fiber.Ctx) (err erro
 	)
 
 	c.Set("Content-Type", "application/x-yaml")
+	c.Set("ETag", etag)
+	c.Set("Cache-Control", cacheControlAggregate)
+	if statusCode == fiber.StatusNotModified {
+		c.Status(statusCode)
+		return nil
+	}
 	return c.Status(statusCode).Send(manifest)
 }
 
@@ -171,9 +180,9 @@ func (p *Proxy) handleGetCatalogVersionFiber(c *fiber.Ctx) (err error) {
 
 	startTime := time.Now()
 
-	version, statusCode, err := p.handleGetCatalogVersion(c.Context())
+	version, statusCode, err := p.handleGetCatalogVersion(c.UserContext())
 	if err != nil {
-		slog.Error("Failed to get catalog version",
+		slog.ErrorContext(c.UserContext(), "Failed to get catalog version",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetCatalogVersion),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, c.Method()),
@@ -181,12 +190,10 @@ func (p *Proxy) handleGetCatalogVersionFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Catalog version request completed",
+	slog.InfoContext(c.UserContext(), "Catalog version request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetCatalogVersion),
 		slog.String(helpers.LogKeyVersion, version),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -204,87 +211,59 @@ func (p *Proxy) handleGetFileFiber(c *fiber.Ctx) (err error) {
 	alias := c.Params("alias")
 	version := c.Params("version")
 	filename := strings.TrimPrefix(c.Params("*"), "/")
+	req := newFileRequestFiber(c)
 
-	var project domain.Project
-	var found bool
-	if project, found, err = p.engine.GetProject(c.Context(), alias); err != nil {
-		slog.Error("Failed to get project for file",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
-			slog.String(helpers.LogKeyFilename, filename),
-			slog.Any(helpers.LogKeyError, err),
-		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
-	}
-	if !found {
-		slog.Debug("Project not found during file streaming",
+	file, statusCode, err := p.handleGetFile(c.UserContext(), alias, version, filename, req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Failed to get file",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
 			slog.String(helpers.LogKeyFilename, filename),
-		)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Project not found",
-		})
-	}
-
-	var src core.Source
-	if src, err = p.engine.GetSource(project.SourceName); err != nil {
-		slog.Error("Source not found",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeySource, project.SourceName),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
+			slog.String(helpers.LogKeyMethod, c.Method()),
+			slog.String(helpers.LogKeyPath, c.Path()),
+			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Source not found",
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
+	defer file.Body.Close()
 
-	slog.Debug("Fetching file from source",
-		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-		slog.String(helpers.LogKeyAlias, alias),
-		slog.String(helpers.LogKeyVersion, version),
-		slog.String(helpers.LogKeyFilename, filename),
-		slog.String(helpers.LogKeySource, project.SourceName),
-	)
-
-	var resp *http.Response
-	if resp, err = src.GetFileResponse(c.Context(), project, version, filename); err != nil {
-		slog.Error("Failed to fetch file from source",
+	if statusCode, _, err = p.serveFile(fiberFileWriter{c: c}, req, alias, file, version); err != nil {
+		slog.ErrorContext(c.UserContext(), "Failed to stream file",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyResolvedVersion, file.Version),
 			slog.String(helpers.LogKeyFilename, filename),
-			slog.String(helpers.LogKeySource, project.SourceName),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
+			slog.String(helpers.LogKeyMethod, c.Method()),
+			slog.String(helpers.LogKeyPath, c.Path()),
+			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
-			"error": "Failed to fetch file",
-		})
+		// Fiber буферизует тело, поэтому даже после начала записи ответ можно заменить ошибкой
+		c.Response().Reset()
+		return writeErrorFiber(c, fiber.StatusBadGateway, err)
 	}
-	defer resp.Body.Close()
 
-	slog.Info("File request completed",
+	slog.InfoContext(c.UserContext(), "File request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyResolvedVersion, file.Version),
 		slog.String(helpers.LogKeyFilename, filename),
-		slog.Int(helpers.LogKeyStatusCode, resp.StatusCode),
+		slog.Int(helpers.LogKeyStatusCode, statusCode),
 		slog.String(helpers.LogKeyMethod, c.Method()),
 		slog.String(helpers.LogKeyPath, c.Path()),
 		slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
-		slog.String("content_type", resp.Header.Get("Content-Type")),
-		slog.String("content_length", resp.Header.Get("Content-Length")),
+		slog.String("content_type", file.ContentType),
+		slog.Int64("content_length", file.ContentLength),
+		slog.Bool("cached", file.Cached),
 	)
 
-	p.copyResponseHeaders(c, resp)
-
-	_, err = io.Copy(c.Response().BodyWriter(), resp.Body)
-	return
+	return nil
 }
 
 func (p *Proxy) handleGetVersionsFiber(c *fiber.Ctx) (err error) {
@@ -292,9 +271,9 @@ func (p *Proxy) handleGetVersionsFiber(c *fiber.Ctx) (err error) {
 	startTime := time.Now()
 	alias := c.Params("alias")
 
-	versions, statusCode, err := p.handleGetVersions(c.Context(), alias)
+	versions, statusCode, err := p.handleGetVersions(c.UserContext(), alias, c.Query("prerelease"), c.Query("constraint"))
 	if err != nil {
-		slog.Error("Failed to get versions",
+		slog.ErrorContext(c.UserContext(), "Failed to get versions",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -303,12 +282,10 @@ func (p *Proxy) handleGetVersionsFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Versions request completed",
+	slog.InfoContext(c.UserContext(), "Versions request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -338,9 +315,9 @@ func (p *Proxy) handleListProjectsFiber(c *fiber.Ctx) (err error) {
 		}
 	}
 
-	projects, total, statusCode, err := p.handleListProjects(c.Context(), limit, offset)
+	projects, total, statusCode, err := p.handleListProjects(c.UserContext(), limit, offset)
 	if err != nil {
-		slog.Error("Failed to list projects",
+		slog.ErrorContext(c.UserContext(), "Failed to list projects",
 			slog.String(helpers.LogKeyAction, helpers.ActionListProjects),
 			slog.Int(helpers.LogKeyLimit, limit),
 			slog.Int(helpers.LogKeyOffset, offset),
@@ -350,12 +327,10 @@ func (p *Proxy) handleListProjectsFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("List projects request completed",
+	slog.InfoContext(c.UserContext(), "List projects request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionListProjects),
 		slog.Int(helpers.LogKeyLimit, limit),
 		slog.Int(helpers.LogKeyOffset, offset),
@@ -379,33 +354,29 @@ func (p *Proxy) handleCreateProjectFiber(c *fiber.Ctx) (err error) {
 
 	var req dto.ProjectCreateRequest
 	if err = c.BodyParser(&req); err != nil {
-		slog.Debug("Invalid request body",
+		slog.DebugContext(c.UserContext(), "Invalid request body",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeInvalidRequestBody, "Invalid request body")
 	}
 
 	if err = helpers.ValidateStruct(&req); err != nil {
-		slog.Debug("Validation failed",
+		slog.DebugContext(c.UserContext(), "Validation failed",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyAlias, req.Alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeValidationFailed, err.Error())
 	}
 
-	statusCode, id, err := p.handleCreateProject(c.Context(), req)
+	statusCode, id, err := p.handleCreateProject(c.UserContext(), req)
 	if err != nil {
-		slog.Error("Failed to create project",
+		slog.ErrorContext(c.UserContext(), "Failed to create project",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyAlias, req.Alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -414,9 +385,7 @@ func (p *Proxy) handleCreateProjectFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
 	args := []any{
@@ -433,7 +402,7 @@ func (p *Proxy) handleCreateProjectFiber(c *fiber.Ctx) (err error) {
 	if req.RepoURL != "" {
 		args = append(args, slog.String(helpers.LogKeyRepoURL, req.RepoURL))
 	}
-	slog.Info("Create project request completed", args...)
+	slog.InfoContext(c.UserContext(), "Create project request completed", args...)
 
 	return c.Status(statusCode).JSON(fiber.Map{"id": id.String()})
 }
@@ -444,21 +413,19 @@ func (p *Proxy) handleGetProjectFiber(c *fiber.Ctx) (err error) {
 	alias := c.Params("alias")
 
 	if err = helpers.ValidateAlias(alias); err != nil {
-		slog.Debug("Invalid alias",
+		slog.DebugContext(c.UserContext(), "Invalid alias",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeInvalidAlias, err.Error())
 	}
 
-	project, found, statusCode, err := p.handleGetProject(c.Context(), alias)
+	project, found, statusCode, err := p.handleGetProject(c.UserContext(), alias)
 	if err != nil {
-		slog.Error("Failed to get project",
+		slog.ErrorContext(c.UserContext(), "Failed to get project",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -467,20 +434,16 @@ func (p *Proxy) handleGetProjectFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 	if !found {
-		slog.Debug("Project not found",
+		slog.DebugContext(c.UserContext(), "Project not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 		)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Project not found",
-		})
+		return writeErrorFiber(c, fiber.StatusNotFound, errs.ErrProjectNotFound)
 	}
 
 	args := []any{
@@ -497,7 +460,7 @@ func (p *Proxy) handleGetProjectFiber(c *fiber.Ctx) (err error) {
 	if project.RepoURL != "" {
 		args = append(args, slog.String(helpers.LogKeyRepoURL, project.RepoURL))
 	}
-	slog.Info("Get project request completed", args...)
+	slog.InfoContext(c.UserContext(), "Get project request completed", args...)
 
 	return c.Status(statusCode).JSON(project)
 }
@@ -508,48 +471,42 @@ func (p *Proxy) handleUpdateProjectFiber(c *fiber.Ctx) (err error) {
 	alias := c.Params("alias")
 
 	if err = helpers.ValidateAlias(alias); err != nil {
-		slog.Debug("Invalid alias",
+		slog.DebugContext(c.UserContext(), "Invalid alias",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeInvalidAlias, err.Error())
 	}
 
 	var req dto.ProjectUpdateRequest
 	if err = c.BodyParser(&req); err != nil {
-		slog.Debug("Invalid request body",
+		slog.DebugContext(c.UserContext(), "Invalid request body",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeInvalidRequestBody, "Invalid request body")
 	}
 
 	if err = helpers.ValidateStruct(&req); err != nil {
-		slog.Debug("Validation failed",
+		slog.DebugContext(c.UserContext(), "Validation failed",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeValidationFailed, err.Error())
 	}
 
-	statusCode, err := p.handleUpdateProject(c.Context(), alias, req)
+	statusCode, err := p.handleUpdateProject(c.UserContext(), alias, req)
 	if err != nil {
-		slog.Error("Failed to update project",
+		slog.ErrorContext(c.UserContext(), "Failed to update project",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -558,9 +515,7 @@ func (p *Proxy) handleUpdateProjectFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
 	args := []any{
@@ -583,7 +538,7 @@ func (p *Proxy) handleUpdateProjectFiber(c *fiber.Ctx) (err error) {
 	if req.Description != nil {
 		args = append(args, slog.String(helpers.LogKeyDescription, *req.Description))
 	}
-	slog.Info("Update project request completed", args...)
+	slog.InfoContext(c.UserContext(), "Update project request completed", args...)
 
 	return c.SendStatus(statusCode)
 }
@@ -594,21 +549,19 @@ func (p *Proxy) handleDeleteProjectFiber(c *fiber.Ctx) (err error) {
 	alias := c.Params("alias")
 
 	if err = helpers.ValidateAlias(alias); err != nil {
-		slog.Debug("Invalid alias",
+		slog.DebugContext(c.UserContext(), "Invalid alias",
 			slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, c.Method()),
 			slog.String(helpers.LogKeyPath, c.Path()),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return writeProblemFiber(c, fiber.StatusBadRequest, codeInvalidAlias, err.Error())
 	}
 
-	statusCode, err := p.handleDeleteProject(c.Context(), alias)
+	statusCode, err := p.handleDeleteProject(c.UserContext(), alias)
 	if err != nil {
-		slog.Error("Failed to delete project",
+		slog.ErrorContext(c.UserContext(), "Failed to delete project",
 			slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -617,12 +570,10 @@ func (p *Proxy) handleDeleteProjectFiber(c *fiber.Ctx) (err error) {
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Delete project request completed",
+	slog.InfoContext(c.UserContext(), "Delete project request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -653,9 +604,9 @@ func (p *Proxy) handleGetManifestDataFiber(c *fiber.Ctx, alias string, version s
 
 	startTime := time.Now()
 
-	manifest, statusCode, err := p.handleGetManifestData(c.Context(), alias, version)
+	manifest, statusCode, err := p.handleGetManifestData(c.UserContext(), alias, version)
 	if err != nil {
-		slog.Error("Failed to get manifest data",
+		slog.ErrorContext(c.UserContext(), "Failed to get manifest data",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifestData),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -665,12 +616,10 @@ func (p *Proxy) handleGetManifestDataFiber(c *fiber.Ctx, alias string, version s
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Manifest data request completed",
+	slog.InfoContext(c.UserContext(), "Manifest data request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifestData),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
@@ -687,9 +636,9 @@ func (p *Proxy) handleGetManifestAggregatedFiber(c *fiber.Ctx, alias string, ver
 
 	startTime := time.Now()
 
-	out, statusCode, err := p.handleGetManifestAggregated(c.Context(), alias, version)
+	out, statusCode, err := p.handleGetManifestAggregated(c.UserContext(), alias, version)
 	if err != nil {
-		slog.Error("Failed to get manifest aggregated",
+		slog.ErrorContext(c.UserContext(), "Failed to get manifest aggregated",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifestAggregated),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -699,12 +648,10 @@ func (p *Proxy) handleGetManifestAggregatedFiber(c *fiber.Ctx, alias string, ver
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		return c.Status(statusCode).JSON(fiber.Map{
-			"error": helpers.GetErrorMessage(err),
-		})
+		return writeErrorFiber(c, statusCode, err)
 	}
 
-	slog.Info("Manifest aggregated request completed",
+	slog.InfoContext(c.UserContext(), "Manifest aggregated request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifestAggregated),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
@@ -717,18 +664,3 @@ func (p *Proxy) handleGetManifestAggregatedFiber(c *fiber.Ctx, alias string, ver
 
 	return c.Status(statusCode).JSON(out)
 }
-
-func (p *Proxy) copyResponseHeaders(c *fiber.Ctx, resp *http.Response) {
-
-	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
-		c.Set("Content-Type", contentType)
-	} else {
-		c.Set("Content-Type", "application/octet-stream")
-	}
-
-	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
-		c.Set("Content-Length", contentLength)
-	}
-
-	c.Set("Cache-Control", "public, max-age=3600")
-}
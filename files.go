@@ -0,0 +1,210 @@
+package tgproxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seniorGolang/tg-proxy/core"
+	"github.com/seniorGolang/tg-proxy/helpers"
+)
+
+// fileRequest — часть запроса файла, от которой зависит ответ: метод, Range и условные заголовки.
+type fileRequest struct {
+	method          string
+	rangeHeader     string
+	ifRange         string
+	ifNoneMatch     string
+	ifModifiedSince string
+}
+
+// fileResponseWriter — минимальный интерфейс ответа, общий для net/http и Fiber, чтобы оба роутера отдавали файлы одинаково.
+type fileResponseWriter interface {
+	SetHeader(key string, value string)
+	WriteHeader(statusCode int)
+	Write(p []byte) (n int, err error)
+}
+
+type netHTTPFileWriter struct {
+	w http.ResponseWriter
+}
+
+type fiberFileWriter struct {
+	c *fiber.Ctx
+}
+
+func newFileRequestNetHTTP(r *http.Request) (req fileRequest) {
+
+	return fileRequest{
+		method:          r.Method,
+		rangeHeader:     r.Header.Get("Range"),
+		ifRange:         r.Header.Get("If-Range"),
+		ifNoneMatch:     r.Header.Get("If-None-Match"),
+		ifModifiedSince: r.Header.Get("If-Modified-Since"),
+	}
+}
+
+func newFileRequestFiber(c *fiber.Ctx) (req fileRequest) {
+
+	return fileRequest{
+		method:          c.Method(),
+		rangeHeader:     c.Get(fiber.HeaderRange),
+		ifRange:         c.Get(fiber.HeaderIfRange),
+		ifNoneMatch:     c.Get(fiber.HeaderIfNoneMatch),
+		ifModifiedSince: c.Get(fiber.HeaderIfModifiedSince),
+	}
+}
+
+// header — заголовки для движка; для HEAD диапазон не нужен.
+func (r fileRequest) header() (header http.Header) {
+
+	header = make(http.Header)
+	if r.method == http.MethodGet && r.rangeHeader != "" {
+		header.Set("Range", r.rangeHeader)
+		if r.ifRange != "" {
+			header.Set("If-Range", r.ifRange)
+		}
+	}
+	if r.ifNoneMatch != "" {
+		header.Set("If-None-Match", r.ifNoneMatch)
+	}
+	if r.ifModifiedSince != "" {
+		header.Set("If-Modified-Since", r.ifModifiedSince)
+	}
+	return
+}
+
+func (fw netHTTPFileWriter) SetHeader(key string, value string) {
+
+	fw.w.Header().Set(key, value)
+}
+
+func (fw netHTTPFileWriter) WriteHeader(statusCode int) {
+
+	fw.w.WriteHeader(statusCode)
+}
+
+func (fw netHTTPFileWriter) Write(p []byte) (n int, err error) {
+
+	return fw.w.Write(p)
+}
+
+func (fw fiberFileWriter) SetHeader(key string, value string) {
+
+	fw.c.Set(key, value)
+}
+
+func (fw fiberFileWriter) WriteHeader(statusCode int) {
+
+	fw.c.Status(statusCode)
+}
+
+func (fw fiberFileWriter) Write(p []byte) (n int, err error) {
+
+	return fw.c.Response().BodyWriter().Write(p)
+}
+
+// serveFile пишет ответ с файлом: 304 по условным заголовкам, 206/416 по Range, иначе 200.
+// Ответы источника 206 и 304 передаются как есть; Range к файлу, который ещё сверяется с контрольной суммой,
+// игнорируется. sent — заголовки уже отправлены, и ошибку можно сообщить клиенту только обрывом ответа.
+func (p *Proxy) serveFile(w fileResponseWriter, req fileRequest, alias string, file *core.File, requestedVersion string) (statusCode int, sent bool, err error) {
+
+	w.SetHeader(headerResolvedVersion, file.Version)
+	if file.Version != requestedVersion {
+		// latest и диапазоны со временем указывают на другой тег — кешировать ответ на клиенте нельзя
+		w.SetHeader("Cache-Control", cacheControlRevalidate)
+	} else {
+		w.SetHeader("Cache-Control", "public, max-age=3600")
+	}
+	if file.ETag != "" {
+		w.SetHeader("ETag", file.ETag)
+	}
+	if !file.LastModified.IsZero() {
+		w.SetHeader("Last-Modified", file.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	switch file.StatusCode {
+	case http.StatusNotModified:
+		w.WriteHeader(http.StatusNotModified)
+		return http.StatusNotModified, true, nil
+	case http.StatusPartialContent:
+		setContentHeaders(w, file.ContentType, file.ContentLength)
+		w.SetHeader("Accept-Ranges", "bytes")
+		w.SetHeader("Content-Range", file.ContentRange)
+		w.WriteHeader(http.StatusPartialContent)
+		return http.StatusPartialContent, true, p.copyFileBody(w, req, alias, file.Body)
+	}
+
+	if helpers.NotModified(req.ifNoneMatch, req.ifModifiedSince, file.ETag, file.LastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return http.StatusNotModified, true, nil
+	}
+
+	size := file.ContentLength
+	if size >= 0 {
+		w.SetHeader("Accept-Ranges", "bytes")
+	}
+
+	// проверяемый поток не режется: диапазон из него дошёл бы до клиента раньше сверки суммы на EOF
+	if req.method == http.MethodGet && req.rangeHeader != "" && !file.Verifying && size >= 0 && helpers.IfRangeSatisfied(req.ifRange, file.ETag, file.LastModified) {
+		start, length, result := helpers.ParseByteRange(req.rangeHeader, size)
+		switch result {
+		case helpers.RangeUnsatisfiable:
+			w.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", size))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return http.StatusRequestedRangeNotSatisfiable, true, nil
+		case helpers.RangeSatisfiable:
+			if err = skipFileBody(file.Body, start); err != nil {
+				return http.StatusBadGateway, false, err
+			}
+			setContentHeaders(w, file.ContentType, length)
+			w.SetHeader("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+			w.WriteHeader(http.StatusPartialContent)
+			return http.StatusPartialContent, true, p.copyFileBody(w, req, alias, io.LimitReader(file.Body, length))
+		}
+	}
+
+	setContentHeaders(w, file.ContentType, size)
+	w.WriteHeader(http.StatusOK)
+	return http.StatusOK, true, p.copyFileBody(w, req, alias, file.Body)
+}
+
+func setContentHeaders(w fileResponseWriter, contentType string, contentLength int64) {
+
+	if contentType != "" {
+		w.SetHeader("Content-Type", contentType)
+	} else {
+		w.SetHeader("Content-Type", "application/octet-stream")
+	}
+	if contentLength >= 0 {
+		w.SetHeader("Content-Length", strconv.FormatInt(contentLength, 10))
+	}
+}
+
+// skipFileBody перематывает тело к началу диапазона: файлы из кеша поддерживают Seek, поток источника вычитывается.
+func skipFileBody(body io.Reader, offset int64) (err error) {
+
+	if offset == 0 {
+		return
+	}
+	if seeker, ok := body.(io.Seeker); ok {
+		_, err = seeker.Seek(offset, io.SeekStart)
+		return
+	}
+	_, err = io.CopyN(io.Discard, body, offset)
+	return
+}
+
+func (p *Proxy) copyFileBody(w fileResponseWriter, req fileRequest, alias string, body io.Reader) (err error) {
+
+	if req.method == http.MethodHead {
+		return
+	}
+	var written int64
+	written, err = io.Copy(w, body)
+	p.observeBytesStreamed(alias, written)
+	return
+}
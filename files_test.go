@@ -0,0 +1,77 @@
+package tgproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/tg-proxy/core"
+)
+
+const testFileBody = "0123456789"
+
+type nopSeekCloser struct {
+	*strings.Reader
+}
+
+func (nopSeekCloser) Close() (err error) {
+
+	return
+}
+
+func serveTestFile(t *testing.T, file *core.File, rangeHeader string) (resp *http.Response, body string) {
+
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	req := fileRequest{method: http.MethodGet, rangeHeader: rangeHeader}
+	if _, _, err := (&Proxy{}).serveFile(netHTTPFileWriter{w: rec}, req, "tool", file, file.Version); err != nil {
+		t.Fatal(err)
+	}
+	resp = rec.Result()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func TestServeFileRange(t *testing.T) {
+
+	file := &core.File{
+		Body:          nopSeekCloser{strings.NewReader(testFileBody)},
+		Version:       "v1.0.0",
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(testFileBody)),
+		Cached:        true,
+	}
+
+	resp, body := serveTestFile(t, file, "bytes=2-4")
+
+	if resp.StatusCode != http.StatusPartialContent || body != "234" {
+		t.Fatalf("expected 206 with %q, got %d with %q", "234", resp.StatusCode, body)
+	}
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "bytes 2-4/10" {
+		t.Fatalf("unexpected Content-Range %q", contentRange)
+	}
+}
+
+func TestServeFileRangeIgnoredWhileVerifying(t *testing.T) {
+
+	// сумма сверяется только на EOF: диапазон из середины потока ушёл бы клиенту непроверенным
+	file := &core.File{
+		Body:          io.NopCloser(strings.NewReader(testFileBody)),
+		Version:       "v1.0.0",
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(testFileBody)),
+		Verifying:     true,
+	}
+
+	resp, body := serveTestFile(t, file, "bytes=2-4")
+
+	if resp.StatusCode != http.StatusOK || body != testFileBody {
+		t.Fatalf("expected whole file with 200, got %d with %q", resp.StatusCode, body)
+	}
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+		t.Fatalf("expected no Content-Range, got %q", contentRange)
+	}
+}
@@ -2,13 +2,17 @@ package tgproxy
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"io"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 
+	"github.com/seniorGolang/tg-proxy/core"
 	"github.com/seniorGolang/tg-proxy/errs"
 	"github.com/seniorGolang/tg-proxy/helpers"
 	"github.com/seniorGolang/tg-proxy/model"
@@ -16,9 +20,53 @@ import (
 	"github.com/seniorGolang/tg-proxy/model/dto"
 )
 
-func (p *Proxy) handleGetManifest(ctx context.Context, alias string, version string) (manifest []byte, statusCode int, err error) {
+// headerResolvedVersion — конкретный тег, к которому привели latest, latest-stable или диапазон из пути запроса.
+const headerResolvedVersion = "X-Tg-Resolved-Version"
 
-	if manifest, err = p.engine.GetManifest(ctx, alias, version, p.manifestSourceBaseURL()); err != nil {
+const (
+	// cacheControlManifest — манифест конкретного тега не меняется, клиент может долго не перезапрашивать его.
+	cacheControlManifest = "public, max-age=86400"
+	// cacheControlAggregate — каталог меняется при любой правке проектов, поэтому срок короткий.
+	cacheControlAggregate = "public, max-age=60"
+	// cacheControlRevalidate — latest и диапазоны со временем указывают на другой тег, ответ проверяется по ETag.
+	cacheControlRevalidate = "no-cache"
+)
+
+func (p *Proxy) handleResolveVersion(ctx context.Context, alias string, version string) (resolved string, statusCode int, err error) {
+
+	if resolved, err = p.engine.ResolveVersion(ctx, alias, version); err != nil {
+		if errors.Is(err, errs.ErrProjectNotFound) {
+			statusCode = http.StatusNotFound
+			return
+		}
+		if errors.Is(err, errs.ErrVersionNotFound) {
+			statusCode = http.StatusNotFound
+			return
+		}
+		if errors.Is(err, errs.ErrInvalidSourceSettings) {
+			statusCode = http.StatusBadRequest
+			return
+		}
+		if errors.Is(err, errs.ErrSourceUnavailable) || errors.Is(err, errs.ErrRateLimited) {
+			statusCode = http.StatusServiceUnavailable
+			return
+		}
+		statusCode = http.StatusInternalServerError
+		return
+	}
+
+	statusCode = http.StatusOK
+	return
+}
+
+// handleGetManifest отдаёт манифест проекта; при совпадении If-None-Match с ETag возвращается 304 без тела.
+func (p *Proxy) handleGetManifest(ctx context.Context, alias string, version string, ifNoneMatch string) (manifest []byte, resolved string, etag string, statusCode int, err error) {
+
+	if resolved, statusCode, err = p.handleResolveVersion(ctx, alias, version); err != nil {
+		return
+	}
+
+	if manifest, err = p.engine.GetManifest(ctx, alias, resolved, p.manifestSourceBaseURL()); err != nil {
 		if errors.Is(err, errs.ErrProjectNotFound) {
 			statusCode = http.StatusNotFound
 			return
@@ -31,39 +79,83 @@ func (p *Proxy) handleGetManifest(ctx context.Context, alias string, version str
 			statusCode = http.StatusBadRequest
 			return
 		}
+		if errors.Is(err, errs.ErrInvalidSourceSettings) {
+			statusCode = http.StatusBadRequest
+			return
+		}
+		if errors.Is(err, errs.ErrSourceUnavailable) || errors.Is(err, errs.ErrRateLimited) {
+			statusCode = http.StatusServiceUnavailable
+			return
+		}
 		statusCode = http.StatusInternalServerError
 		return
 	}
 
+	etag = manifestETag(manifest)
+	if helpers.ETagMatch(ifNoneMatch, etag, true) {
+		statusCode = http.StatusNotModified
+		return
+	}
+
 	statusCode = http.StatusOK
 	return
 }
 
-func (p *Proxy) handleGetAggregateManifest(ctx context.Context) (manifest []byte, statusCode int, err error) {
+// handleGetAggregateManifest отдаёт агрегированный манифест; ETag считается по его содержимому.
+func (p *Proxy) handleGetAggregateManifest(ctx context.Context, ifNoneMatch string) (manifest []byte, etag string, statusCode int, err error) {
 
 	if manifest, err = p.engine.GetAggregateManifest(ctx, p.manifestSourceBaseURL()); err != nil {
 		statusCode = http.StatusInternalServerError
 		return
 	}
 
+	// агрегированный манифест может быть взят из кеша, записанного ещё при прошлой версии каталога,
+	// поэтому ETag строится только по содержимому
+	etag = manifestETag(manifest)
+	if helpers.ETagMatch(ifNoneMatch, etag, true) {
+		statusCode = http.StatusNotModified
+		return
+	}
+
 	statusCode = http.StatusOK
 	return
 }
 
+// manifestETag — сильный ETag по содержимому отрендеренного манифеста.
+func manifestETag(manifest []byte) (etag string) {
+
+	sum := sha256.Sum256(manifest)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func manifestCacheControl(requested string, resolved string) (cacheControl string) {
+
+	if requested != resolved {
+		return cacheControlRevalidate
+	}
+	return cacheControlManifest
+}
+
 func (p *Proxy) handleGetCatalogVersion(ctx context.Context) (version string, statusCode int, err error) {
 
 	if version, err = p.engine.GetCatalogVersion(ctx); err != nil {
 		statusCode = http.StatusInternalServerError
 		return
 	}
+	p.observeCatalogVersion(version)
 
 	statusCode = http.StatusOK
 	return
 }
 
-func (p *Proxy) handleGetFile(ctx context.Context, alias string, version string, filename string) (stream io.ReadCloser, statusCode int, err error) {
+func (p *Proxy) handleGetFile(ctx context.Context, alias string, version string, filename string, req fileRequest) (file *core.File, statusCode int, err error) {
 
-	if stream, err = p.engine.GetFile(ctx, alias, version, filename); err != nil {
+	getFile := p.engine.GetFile
+	if req.method == http.MethodHead {
+		// на HEAD тело не нужно — метаданные берутся из кеша или из HEAD-ответа источника
+		getFile = p.engine.HeadFile
+	}
+	if file, err = getFile(ctx, alias, version, filename, req.header()); err != nil {
 		if errors.Is(err, errs.ErrProjectNotFound) {
 			statusCode = http.StatusNotFound
 			return
@@ -72,10 +164,27 @@ func (p *Proxy) handleGetFile(ctx context.Context, alias string, version string,
 			statusCode = http.StatusNotFound
 			return
 		}
+		if errors.Is(err, errs.ErrFileNotFound) {
+			statusCode = http.StatusNotFound
+			return
+		}
 		if errors.Is(err, errs.ErrVersionMismatch) {
 			statusCode = http.StatusBadRequest
 			return
 		}
+		if errors.Is(err, errs.ErrInvalidSourceSettings) {
+			statusCode = http.StatusBadRequest
+			return
+		}
+		if errors.Is(err, errs.ErrSourceUnavailable) || errors.Is(err, errs.ErrRateLimited) {
+			statusCode = http.StatusServiceUnavailable
+			return
+		}
+		if errors.Is(err, errs.ErrUnsupportedChecksum) {
+			// сумму из манифеста нечем проверить — файл не отдаётся, как и при несовпадении суммы
+			statusCode = http.StatusBadGateway
+			return
+		}
 		statusCode = http.StatusBadGateway
 		return
 	}
@@ -84,17 +193,41 @@ func (p *Proxy) handleGetFile(ctx context.Context, alias string, version string,
 	return
 }
 
-func (p *Proxy) handleGetVersions(ctx context.Context, alias string) (versions []string, statusCode int, err error) {
+// handleGetVersions фильтрует версии по prerelease (пусто — пререлизы включаются) и constraint;
+// неразборчивые значения обоих параметров — 400.
+func (p *Proxy) handleGetVersions(ctx context.Context, alias string, prerelease string, constraint string) (versions []string, statusCode int, err error) {
+
+	includePrerelease := true
+	if prerelease != "" {
+		if includePrerelease, err = strconv.ParseBool(prerelease); err != nil {
+			err = fmt.Errorf("%w: %q", errs.ErrInvalidPrerelease, prerelease)
+			statusCode = http.StatusBadRequest
+			return
+		}
+	}
 
 	if versions, err = p.engine.GetVersions(ctx, alias); err != nil {
 		if errors.Is(err, errs.ErrProjectNotFound) {
 			statusCode = http.StatusNotFound
 			return
 		}
+		if errors.Is(err, errs.ErrInvalidSourceSettings) {
+			statusCode = http.StatusBadRequest
+			return
+		}
+		if errors.Is(err, errs.ErrSourceUnavailable) || errors.Is(err, errs.ErrRateLimited) {
+			statusCode = http.StatusServiceUnavailable
+			return
+		}
 		statusCode = http.StatusInternalServerError
 		return
 	}
 
+	if versions, err = helpers.FilterVersions(versions, includePrerelease, constraint); err != nil {
+		statusCode = http.StatusBadRequest
+		return
+	}
+
 	statusCode = http.StatusOK
 	return
 }
@@ -117,6 +250,9 @@ func (p *Proxy) handleCreateProject(ctx context.Context, req dto.ProjectCreateRe
 		}
 		return http.StatusInternalServerError, uuid.Nil, err
 	}
+	if err = validateSourceSettings(src, project.Settings); err != nil {
+		return http.StatusBadRequest, uuid.Nil, err
+	}
 
 	id, err = p.engine.CreateProject(ctx, project)
 	if err != nil {
@@ -129,14 +265,23 @@ func (p *Proxy) handleCreateProject(ctx context.Context, req dto.ProjectCreateRe
 	return http.StatusCreated, id, nil
 }
 
+// validateSourceSettings проверяет настройки проекта, если источник умеет это делать (core.SettingsValidator).
+func validateSourceSettings(src core.Source, settings map[string]string) (err error) {
+
+	if validator, ok := src.(core.SettingsValidator); ok {
+		return validator.ValidateSettings(settings)
+	}
+	return
+}
+
 func (p *Proxy) handleGetProject(ctx context.Context, alias string) (project dto.ProjectResponse, found bool, statusCode int, err error) {
 
-	slog.Info("Getting project",
+	slog.InfoContext(ctx, "Getting project",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 		slog.String(helpers.LogKeyAlias, alias),
 	)
 
-	slog.Debug("Project request details",
+	slog.DebugContext(ctx, "Project request details",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 		slog.String(helpers.LogKeyAlias, alias),
 	)
@@ -148,7 +293,7 @@ func (p *Proxy) handleGetProject(ctx context.Context, alias string) (project dto
 	}
 	if !found {
 		statusCode = http.StatusNotFound
-		slog.Debug("Project not found",
+		slog.DebugContext(ctx, "Project not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 		)
@@ -197,6 +342,9 @@ func (p *Proxy) handleUpdateProject(ctx context.Context, alias string, req dto.P
 	if req.SourceName != nil {
 		currentProject.SourceName = updateProject.SourceName
 	}
+	if req.Settings != nil {
+		currentProject.Settings = updateProject.Settings
+	}
 
 	src, err := p.engine.GetSource(currentProject.SourceName)
 	if err != nil {
@@ -212,6 +360,9 @@ func (p *Proxy) handleUpdateProject(ctx context.Context, alias string, req dto.P
 		}
 		return http.StatusInternalServerError, err
 	}
+	if err = validateSourceSettings(src, currentProject.Settings); err != nil {
+		return http.StatusBadRequest, err
+	}
 
 	if err = p.engine.UpdateProject(ctx, alias, currentProject); err != nil {
 		if errors.Is(err, errs.ErrProjectNotFound) {
@@ -269,6 +420,14 @@ func (p *Proxy) handleGetManifestData(ctx context.Context, alias string, version
 			statusCode = http.StatusNotFound
 			return
 		}
+		if errors.Is(err, errs.ErrInvalidSourceSettings) {
+			statusCode = http.StatusBadRequest
+			return
+		}
+		if errors.Is(err, errs.ErrSourceUnavailable) || errors.Is(err, errs.ErrRateLimited) {
+			statusCode = http.StatusServiceUnavailable
+			return
+		}
 		statusCode = http.StatusInternalServerError
 		return
 	}
@@ -287,6 +446,14 @@ func (p *Proxy) handleGetManifestAggregated(ctx context.Context, alias string, v
 			statusCode = http.StatusNotFound
 			return
 		}
+		if errors.Is(err, errs.ErrInvalidSourceSettings) {
+			statusCode = http.StatusBadRequest
+			return
+		}
+		if errors.Is(err, errs.ErrSourceUnavailable) || errors.Is(err, errs.ErrRateLimited) {
+			statusCode = http.StatusServiceUnavailable
+			return
+		}
 		statusCode = http.StatusInternalServerError
 		return
 	}
@@ -313,7 +480,7 @@ func (p *Proxy) ListProjects(ctx context.Context, limit int, offset int) (projec
 func (p *Proxy) GetVersions(ctx context.Context, alias string) (versions []string, err error) {
 
 	var statusCode int
-	versions, statusCode, err = p.handleGetVersions(ctx, alias)
+	versions, statusCode, err = p.handleGetVersions(ctx, alias, "", "")
 	if err != nil {
 		return
 	}
@@ -0,0 +1,96 @@
+package tgproxy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/seniorGolang/tg-proxy/core"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/dto"
+)
+
+// HealthCheckSources добавляет в проверку готовности доступность зарегистрированных источников.
+// По умолчанию проверяются только хранилище и кеш: недоступность GitHub не должна выводить реплику из балансировки.
+func HealthCheckSources() (opt ProxyOption) {
+	return func(p *Proxy) {
+		p.healthCheckSources = true
+	}
+}
+
+// SetHealthRoutes монтирует prefix/live (процесс жив) и prefix/ready (зависимости доступны, иначе 503).
+// Авторизации нет: маршруты предназначены для оркестратора.
+func (p *Proxy) SetHealthRoutes(mux *http.ServeMux, prefix string) {
+
+	base := strings.TrimSuffix(prefix, "/")
+	if base == "" {
+		base = "/"
+	}
+
+	mux.HandleFunc("GET "+path.Join(base, "live"), func(w http.ResponseWriter, r *http.Request) {
+		writeHealthNetHTTP(w, http.StatusOK, dto.HealthResponse{Status: core.HealthStatusUp})
+	})
+	mux.HandleFunc("GET "+path.Join(base, "ready"), func(w http.ResponseWriter, r *http.Request) {
+		statusCode, resp := p.handleReadiness(r.Context())
+		writeHealthNetHTTP(w, statusCode, resp)
+	})
+}
+
+func (p *Proxy) SetHealthRoutesFiber(app *fiber.App, prefix string) {
+
+	group := app.Group(prefix)
+	group.Get("/live", func(c *fiber.Ctx) (err error) {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Status(fiber.StatusOK).JSON(dto.HealthResponse{Status: core.HealthStatusUp})
+	})
+	group.Get("/ready", func(c *fiber.Ctx) (err error) {
+		statusCode, resp := p.handleReadiness(c.UserContext())
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Status(statusCode).JSON(resp)
+	})
+}
+
+func (p *Proxy) handleReadiness(ctx context.Context) (statusCode int, resp dto.HealthResponse) {
+
+	checks, ready := p.engine.CheckReadiness(ctx, p.healthCheckSources)
+
+	resp.Status = core.HealthStatusUp
+	statusCode = http.StatusOK
+	if !ready {
+		resp.Status = core.HealthStatusDown
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	for _, check := range checks {
+		resp.Components = append(resp.Components, dto.ComponentHealth{
+			Name:      check.Name,
+			Status:    check.Status,
+			LatencyMs: float64(check.Latency.Microseconds()) / 1000,
+			Error:     check.Error,
+		})
+		if check.Status == core.HealthStatusDegraded && ready {
+			resp.Status = core.HealthStatusDegraded
+		}
+		if check.Status != core.HealthStatusUp {
+			slog.WarnContext(ctx, "Readiness check failed",
+				slog.String(helpers.LogKeyComponent, check.Name),
+				slog.Duration(helpers.LogKeyDuration, check.Latency),
+				slog.String(helpers.LogKeyError, check.Error),
+			)
+		}
+	}
+	return
+}
+
+func writeHealthNetHTTP(w http.ResponseWriter, statusCode int, resp dto.HealthResponse) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -64,6 +64,12 @@ func (a *StaticKeyAuth) AuthorizeFiber(c *fiber.Ctx) (err error) {
 	return
 }
 
+// Principal — у статического ключа нет имени клиента, поэтому в журнал попадает сам способ авторизации.
+func (a *StaticKeyAuth) Principal(header http.Header) (principal string) {
+
+	return "api_key"
+}
+
 func NewStaticKeyAuth(key string, headerName string) (auth *StaticKeyAuth) {
 	return &StaticKeyAuth{key: key, header: headerName}
 }
@@ -117,6 +123,11 @@ func (a *BasicAuth) AuthorizeFiber(c *fiber.Ctx) (err error) {
 	return
 }
 
+func (a *BasicAuth) Principal(header http.Header) (principal string) {
+
+	return a.username
+}
+
 func NewBasicAuth(username string, password string) (auth *BasicAuth) {
 	return &BasicAuth{username: username, password: password}
 }
@@ -185,6 +196,19 @@ func (a *JWTAuth) AuthorizeFiber(c *fiber.Ctx) (err error) {
 	return
 }
 
+// Principal возвращает subject токена; подпись уже проверена в Authorize.
+func (a *JWTAuth) Principal(header http.Header) (principal string) {
+
+	tokenString := strings.TrimPrefix(header.Get("Authorization"), bearerAuthPrefix)
+
+	var claims jwt.MapClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims); err != nil {
+		return
+	}
+	principal, _ = claims.GetSubject()
+	return
+}
+
 func NewJWTAuth(publicKeyPEM string) (auth *JWTAuth, err error) {
 
 	var publicKey *rsa.PublicKey
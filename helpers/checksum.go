@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+)
+
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+	ChecksumSHA1   = "sha1"
+	ChecksumMD5    = "md5"
+)
+
+// ParseChecksum разбирает контрольную сумму из манифеста: "sha256:<hex>" или hex без префикса,
+// тогда алгоритм определяется по длине.
+func ParseChecksum(checksum string) (algorithm string, sum []byte, err error) {
+
+	value := strings.TrimSpace(checksum)
+	if idx := strings.IndexByte(value, ':'); idx >= 0 {
+		algorithm = strings.ToLower(strings.ReplaceAll(value[:idx], "-", ""))
+		value = value[idx+1:]
+	}
+
+	if sum, err = hex.DecodeString(value); err != nil || len(sum) == 0 {
+		err = fmt.Errorf("%w: %q", errs.ErrUnsupportedChecksum, checksum)
+		return
+	}
+
+	if algorithm == "" {
+		switch len(sum) {
+		case md5.Size:
+			algorithm = ChecksumMD5
+		case sha1.Size:
+			algorithm = ChecksumSHA1
+		case sha256.Size:
+			algorithm = ChecksumSHA256
+		case sha512.Size:
+			algorithm = ChecksumSHA512
+		}
+	}
+
+	var h hash.Hash
+	if h, err = NewChecksumHash(algorithm); err != nil {
+		return
+	}
+	if h.Size() != len(sum) {
+		err = fmt.Errorf("%w: %s checksum must be %d bytes", errs.ErrUnsupportedChecksum, algorithm, h.Size())
+		return
+	}
+	return
+}
+
+func NewChecksumHash(algorithm string) (h hash.Hash, err error) {
+
+	switch algorithm {
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumSHA512:
+		return sha512.New(), nil
+	case ChecksumSHA1:
+		return sha1.New(), nil
+	case ChecksumMD5:
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("%w: algorithm %q", errs.ErrUnsupportedChecksum, algorithm)
+}
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RangeResult int
+
+const (
+	// RangeIgnored — заголовок отсутствует, некорректен или содержит несколько диапазонов: отдаётся весь файл.
+	RangeIgnored RangeResult = iota
+	RangeSatisfiable
+	RangeUnsatisfiable
+)
+
+// ETagMatch проверяет ETag по списку из If-None-Match/If-Match. При weak сравнение слабое (RFC 9110, 8.8.3.2):
+// префикс W/ не учитывается.
+func ETagMatch(header string, etag string, weak bool) (match bool) {
+
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+		if candidate == etag && !strings.HasPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified — условный GET можно завершить ответом 304: If-None-Match, а при его отсутствии If-Modified-Since.
+func NotModified(ifNoneMatch string, ifModifiedSince string, etag string, lastModified time.Time) (notModified bool) {
+
+	if ifNoneMatch != "" {
+		return ETagMatch(ifNoneMatch, etag, true)
+	}
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// IfRangeSatisfied — Range применяется: If-Range пуст или совпадает с ETag (строго) либо с Last-Modified.
+func IfRangeSatisfied(ifRange string, etag string, lastModified time.Time) (ok bool) {
+
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return ETagMatch(ifRange, etag, false)
+	}
+	since, err := http.ParseTime(ifRange)
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return lastModified.Truncate(time.Second).Equal(since)
+}
+
+// ParseByteRange разбирает Range для тела размером size. Поддерживается один диапазон bytes=a-b, bytes=a- и bytes=-n.
+func ParseByteRange(header string, size int64) (start int64, length int64, result RangeResult) {
+
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, RangeIgnored
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, RangeIgnored
+	}
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, RangeIgnored
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, RangeUnsatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, suffix, RangeSatisfiable
+	}
+
+	var err error
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil || start < 0 {
+		return 0, 0, RangeIgnored
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, RangeIgnored
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, RangeUnsatisfiable
+	}
+	return start, end - start + 1, RangeSatisfiable
+}
@@ -0,0 +1,289 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+)
+
+const (
+	opEqual          = "="
+	opNotEqual       = "!="
+	opGreater        = ">"
+	opGreaterOrEqual = ">="
+	opLess           = "<"
+	opLessOrEqual    = "<="
+)
+
+// operators упорядочены так, чтобы двухсимвольные проверялись раньше односимвольных.
+var operators = []string{">=", "<=", "!=", "==", "~>", ">", "<", "=", "~", "^"}
+
+type comparator struct {
+	op      string
+	version Version
+}
+
+// VersionConstraint — ограничение на версии: наборы компараторов через пробел (или запятую) объединяются по И,
+// наборы через "||" — по ИЛИ. Поддерживаются =, !=, >, >=, <, <=, ~, ^, диапазоны "a - b" и x-диапазоны (1.2.x).
+type VersionConstraint struct {
+	sets [][]comparator
+}
+
+func ParseConstraint(constraint string) (c VersionConstraint, err error) {
+
+	for _, rawSet := range strings.Split(constraint, "||") {
+		tokens := strings.Fields(strings.ReplaceAll(rawSet, ",", " "))
+		tokens = joinOperatorTokens(tokens)
+
+		set := make([]comparator, 0, len(tokens))
+		for i := 0; i < len(tokens); i++ {
+			if i+2 < len(tokens) && tokens[i+1] == "-" {
+				var cmps []comparator
+				if cmps, err = expandHyphenRange(tokens[i], tokens[i+2]); err != nil {
+					return
+				}
+				set = append(set, cmps...)
+				i += 2
+				continue
+			}
+			var cmps []comparator
+			if cmps, err = expandComparator(tokens[i]); err != nil {
+				return
+			}
+			set = append(set, cmps...)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return
+}
+
+// Check разбирает тег и проверяет его; не-semver теги не подходят ни под одно ограничение.
+func (c VersionConstraint) Check(version string) (ok bool) {
+
+	v, parsed := ParseVersion(version)
+	if !parsed {
+		return false
+	}
+	return c.Match(v)
+}
+
+// Match следует правилу npm: пререлиз подходит, только если в наборе есть компаратор с пререлизом той же major.minor.patch.
+func (c VersionConstraint) Match(v Version) (ok bool) {
+
+	for _, set := range c.sets {
+		if matchSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSet(set []comparator, v Version) (ok bool) {
+
+	for _, cmp := range set {
+		if !cmp.match(v) {
+			return false
+		}
+	}
+
+	if !v.IsPrerelease() {
+		return true
+	}
+
+	for _, cmp := range set {
+		if cmp.version.IsPrerelease() &&
+			cmp.version.Major == v.Major && cmp.version.Minor == v.Minor && cmp.version.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmp comparator) match(v Version) (ok bool) {
+
+	result := CompareVersions(v, cmp.version)
+	switch cmp.op {
+	case opEqual:
+		return result == 0
+	case opNotEqual:
+		return result != 0
+	case opGreater:
+		return result > 0
+	case opGreaterOrEqual:
+		return result >= 0
+	case opLess:
+		return result < 0
+	case opLessOrEqual:
+		return result <= 0
+	}
+	return false
+}
+
+func joinOperatorTokens(tokens []string) (joined []string) {
+
+	joined = make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if isOperator(tokens[i]) && i+1 < len(tokens) {
+			joined = append(joined, tokens[i]+tokens[i+1])
+			i++
+			continue
+		}
+		joined = append(joined, tokens[i])
+	}
+	return
+}
+
+func isOperator(token string) (ok bool) {
+
+	for _, op := range operators {
+		if token == op {
+			return true
+		}
+	}
+	return false
+}
+
+func splitOperator(token string) (op string, rest string) {
+
+	for _, candidate := range operators {
+		if strings.HasPrefix(token, candidate) {
+			return candidate, strings.TrimSpace(token[len(candidate):])
+		}
+	}
+	return "", token
+}
+
+func expandHyphenRange(from string, to string) (cmps []comparator, err error) {
+
+	lower, lowerSpecified, ok := parsePartialVersion(from, true)
+	if !ok {
+		err = fmt.Errorf("%w: %s", errs.ErrInvalidVersionConstraint, from)
+		return
+	}
+	upper, upperSpecified, ok := parsePartialVersion(to, true)
+	if !ok {
+		err = fmt.Errorf("%w: %s", errs.ErrInvalidVersionConstraint, to)
+		return
+	}
+
+	if lowerSpecified > 0 {
+		cmps = append(cmps, comparator{op: opGreaterOrEqual, version: lower})
+	}
+	switch {
+	case upperSpecified == 3:
+		cmps = append(cmps, comparator{op: opLessOrEqual, version: upper})
+	case upperSpecified > 0:
+		cmps = append(cmps, comparator{op: opLess, version: upperBound(upper, upperSpecified)})
+	}
+	return
+}
+
+func expandComparator(token string) (cmps []comparator, err error) {
+
+	op, rest := splitOperator(token)
+	v, specified, ok := parsePartialVersion(rest, true)
+	if !ok {
+		err = fmt.Errorf("%w: %s", errs.ErrInvalidVersionConstraint, token)
+		return
+	}
+
+	// specified == 0 — "*" или "x": без ограничений сверху и снизу.
+	switch op {
+	case "", "=", "==":
+		if specified == 3 {
+			return []comparator{{op: opEqual, version: v}}, nil
+		}
+		if specified == 0 {
+			return nil, nil
+		}
+		return []comparator{
+			{op: opGreaterOrEqual, version: v},
+			{op: opLess, version: upperBound(v, specified)},
+		}, nil
+	case "!=":
+		if specified != 3 {
+			err = fmt.Errorf("%w: %s requires a full version", errs.ErrInvalidVersionConstraint, token)
+			return
+		}
+		return []comparator{{op: opNotEqual, version: v}}, nil
+	case ">":
+		if specified == 0 {
+			err = fmt.Errorf("%w: %s", errs.ErrInvalidVersionConstraint, token)
+			return
+		}
+		if specified == 3 {
+			return []comparator{{op: opGreater, version: v}}, nil
+		}
+		next := upperBound(v, specified)
+		next.Prerelease = nil
+		return []comparator{{op: opGreaterOrEqual, version: next}}, nil
+	case ">=":
+		if specified == 0 {
+			return nil, nil
+		}
+		return []comparator{{op: opGreaterOrEqual, version: v}}, nil
+	case "<":
+		if specified == 0 {
+			err = fmt.Errorf("%w: %s", errs.ErrInvalidVersionConstraint, token)
+			return
+		}
+		return []comparator{{op: opLess, version: v}}, nil
+	case "<=":
+		if specified == 0 {
+			return nil, nil
+		}
+		if specified == 3 {
+			return []comparator{{op: opLessOrEqual, version: v}}, nil
+		}
+		return []comparator{{op: opLess, version: upperBound(v, specified)}}, nil
+	case "~", "~>":
+		if specified == 0 {
+			return nil, nil
+		}
+		level := 2
+		if specified == 1 {
+			level = 1
+		}
+		return []comparator{
+			{op: opGreaterOrEqual, version: v},
+			{op: opLess, version: upperBound(v, level)},
+		}, nil
+	case "^":
+		if specified == 0 {
+			return nil, nil
+		}
+		var level int
+		switch {
+		case v.Major > 0 || specified == 1:
+			level = 1
+		case v.Minor > 0 || specified == 2:
+			level = 2
+		default:
+			level = 3
+		}
+		return []comparator{
+			{op: opGreaterOrEqual, version: v},
+			{op: opLess, version: upperBound(v, level)},
+		}, nil
+	}
+
+	err = fmt.Errorf("%w: %s", errs.ErrInvalidVersionConstraint, token)
+	return
+}
+
+// upperBound возвращает исключающую верхнюю границу с суффиксом "-0", чтобы пререлизы границы (2.0.0-rc) в диапазон не попадали.
+func upperBound(v Version, level int) (bound Version) {
+
+	switch level {
+	case 1:
+		bound = Version{Major: v.Major + 1}
+	case 2:
+		bound = Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		bound = Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	bound.Prerelease = []string{"0"}
+	return
+}
@@ -0,0 +1,104 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+)
+
+func TestConstraintCheck(t *testing.T) {
+
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		// ^ на нулевой major фиксирует первую ненулевую часть
+		{"^0.2", "0.2.9", true},
+		{"^0.2", "0.3.0", false},
+		{"^0.2.3", "0.2.2", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.x", "0.9.0", true},
+		{"^0.x", "1.0.0", false},
+		{"^1.2", "1.9.0", true},
+		{"^1.2", "2.0.0", false},
+		// ~ с одной major — вся major, иначе — minor
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{"~1", "0.9.0", false},
+		{"~1.2", "1.2.9", true},
+		{"~1.2", "1.3.0", false},
+		{"~>1.2.3", "1.2.5", true},
+		// верхняя граница дефисного диапазона с частичной версией — исключающая
+		{"1.2 - 2", "1.2.0", true},
+		{"1.2 - 2", "2.9.9", true},
+		{"1.2 - 2", "3.0.0", false},
+		{"1.2 - 2", "1.1.9", false},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		// > с частичной версией — следующая версия того же уровня
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{">1", "1.9.9", false},
+		{">1", "2.0.0", true},
+		{">1.2.3", "1.2.4", true},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"<1.2", "1.1.9", true},
+		{"<1.2", "1.2.0", false},
+		{"1.2.x", "1.2.5", true},
+		{"1.2.x", "1.3.0", false},
+		{"*", "1.0.0", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"!=1.2.3", "1.2.4", true},
+		{">=1.2, <2", "1.5.0", true},
+		{">= 1.2 < 2", "2.0.0", false},
+		{"1.x || >=3", "2.0.0", false},
+		{"1.x || >=3", "3.1.0", true},
+		{"v1.2.3", "1.2.3+build.7", true},
+		// пререлиз подходит, только если в наборе есть пререлиз той же major.minor.patch
+		{">=1.2.0", "1.3.0-rc.1", false},
+		{"*", "1.0.0-rc.1", false},
+		{">=1.3.0-rc.1", "1.3.0-rc.2", true},
+		{">=1.3.0-rc.1", "1.3.0-beta", false},
+		{">=1.3.0-rc.1", "1.4.0-rc.1", false},
+		{">=1.3.0-rc.1", "1.4.0", true},
+		{"^1.2", "2.0.0-rc.1", false},
+		{"1.2 - 2", "3.0.0-rc.1", false},
+		{"^1.0.0-beta || ^2.0.0-rc.1", "2.0.0-rc.2", true},
+		// не-semver теги не подходят ни под одно ограничение
+		{"*", "main", false},
+		{">=0", "nightly", false},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.constraint, err)
+		}
+		if got := c.Check(tt.version); got != tt.want {
+			t.Errorf("%q.Check(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+
+	for _, constraint := range []string{
+		"abc",
+		"!=1.2",
+		">*",
+		"<x",
+		"1.2-rc",
+		"1.x.3",
+		"1.2.3.4",
+		"1.2.3 - x.y",
+		"=>1.2",
+	} {
+		if _, err := ParseConstraint(constraint); !errors.Is(err, errs.ErrInvalidVersionConstraint) {
+			t.Errorf("%q: expected ErrInvalidVersionConstraint, got %v", constraint, err)
+		}
+	}
+}
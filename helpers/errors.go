@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/seniorGolang/tg-proxy/errs"
 )
@@ -29,7 +30,7 @@ func ExtractStatusCode(err error) (statusCode int, found bool) {
 		return 0, false
 	}
 
-	if !errors.Is(err, errs.ErrGitLabAPI) && !errors.Is(err, errs.ErrGitHubAPI) {
+	if !isSourceAPIError(err) {
 		return 0, false
 	}
 
@@ -62,9 +63,18 @@ func GetErrorMessage(err error) (message string) {
 	if errors.Is(err, errs.ErrVersionNotFound) {
 		return "Version not found"
 	}
+	if errors.Is(err, errs.ErrFileNotFound) {
+		return "File not found"
+	}
 	if errors.Is(err, errs.ErrVersionMismatch) {
 		return "Version mismatch"
 	}
+	if errors.Is(err, errs.ErrInvalidVersionConstraint) {
+		return "Invalid version constraint"
+	}
+	if errors.Is(err, errs.ErrInvalidPrerelease) {
+		return "prerelease must be a boolean"
+	}
 	if errors.Is(err, errs.ErrProjectAlreadyExists) {
 		return "Project already exists"
 	}
@@ -74,14 +84,36 @@ func GetErrorMessage(err error) (message string) {
 	if errors.Is(err, errs.ErrRepoURLSourceMismatch) {
 		return "repo_url must be on the same domain and scheme as the source"
 	}
+	if errors.Is(err, errs.ErrInvalidSourceSettings) {
+		// в тексте ошибки — какая настройка не подошла
+		return err.Error()
+	}
+	if errors.Is(err, errs.ErrChecksumMismatch) {
+		return "File checksum does not match manifest"
+	}
+	if errors.Is(err, errs.ErrUnsupportedChecksum) {
+		return "File checksum in manifest has unsupported format"
+	}
 	if errors.Is(err, errs.ErrManifestParseError) {
 		return "Failed to parse manifest"
 	}
 	if errors.Is(err, errs.ErrManifestMarshalError) {
 		return "Failed to marshal manifest"
 	}
+	if errors.Is(err, errs.ErrSourceUnavailable) {
+		return "Source temporarily unavailable"
+	}
+	if errors.Is(err, errs.ErrRateLimited) {
+		return "Source rate limit exceeded, retry later"
+	}
+	if errors.Is(err, errs.ErrUnauthorized) {
+		return "Unauthorized"
+	}
+	if errors.Is(err, errs.ErrForbidden) {
+		return "Forbidden"
+	}
 
-	if errors.Is(err, errs.ErrGitLabAPI) || errors.Is(err, errs.ErrGitHubAPI) {
+	if isSourceAPIError(err) {
 		if statusCode, found := ExtractStatusCode(err); found {
 			switch statusCode {
 			case 404:
@@ -99,3 +131,66 @@ func GetErrorMessage(err error) (message string) {
 
 	return "Internal server error"
 }
+
+// GetErrorCode возвращает машиночитаемый код ошибки для ответа клиенту.
+func GetErrorCode(err error) (code string) {
+
+	switch {
+	case err == nil:
+		return "unknown_error"
+	case errors.Is(err, errs.ErrProjectNotFound):
+		return "project_not_found"
+	case errors.Is(err, errs.ErrVersionNotFound):
+		return "version_not_found"
+	case errors.Is(err, errs.ErrFileNotFound):
+		return "file_not_found"
+	case errors.Is(err, errs.ErrVersionMismatch):
+		return "version_mismatch"
+	case errors.Is(err, errs.ErrInvalidVersionConstraint):
+		return "invalid_version_constraint"
+	case errors.Is(err, errs.ErrInvalidPrerelease):
+		return "invalid_prerelease"
+	case errors.Is(err, errs.ErrProjectAlreadyExists):
+		return "project_already_exists"
+	case errors.Is(err, errs.ErrSourceNotFound):
+		return "source_not_found"
+	case errors.Is(err, errs.ErrRepoURLSourceMismatch):
+		return "repo_url_source_mismatch"
+	case errors.Is(err, errs.ErrInvalidSourceSettings):
+		return "invalid_source_settings"
+	case errors.Is(err, errs.ErrChecksumMismatch):
+		return "checksum_mismatch"
+	case errors.Is(err, errs.ErrUnsupportedChecksum):
+		return "unsupported_checksum"
+	case errors.Is(err, errs.ErrManifestParseError):
+		return "manifest_parse_error"
+	case errors.Is(err, errs.ErrManifestMarshalError):
+		return "manifest_marshal_error"
+	case errors.Is(err, errs.ErrSourceUnavailable):
+		return "source_unavailable"
+	case errors.Is(err, errs.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, errs.ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, errs.ErrForbidden):
+		return "forbidden"
+	case isSourceAPIError(err):
+		return "source_api_error"
+	}
+	return "internal_error"
+}
+
+// RetryAfter возвращает паузу до повтора, если ошибка её знает (например, *errs.RateLimitError).
+func RetryAfter(err error) (delay time.Duration, ok bool) {
+
+	var retryable interface{ RetryAfter() time.Duration }
+	if errors.As(err, &retryable) {
+		return retryable.RetryAfter(), true
+	}
+	return
+}
+
+func isSourceAPIError(err error) (ok bool) {
+
+	return errors.Is(err, errs.ErrGitLabAPI) || errors.Is(err, errs.ErrGitHubAPI) || errors.Is(err, errs.ErrGiteaAPI)
+}
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// FileRequestHeaders — заголовки клиента, которые можно передать источнику при запросе файла.
+var FileRequestHeaders = []string{"Range", "If-Range", "If-None-Match", "If-Modified-Since"}
+
+// ApplyRequestHeaders добавляет к запросу заголовки из header (nil — ничего не добавляет).
+func ApplyRequestHeaders(req *http.Request, header http.Header) {
+
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
+
+// IsFileResponseStatus — ответ источника на запрос файла, который можно отдать клиенту:
+// 200, а также 206 и 304 в ответ на переданные Range и условные заголовки.
+func IsFileResponseStatus(statusCode int) (ok bool) {
+
+	return statusCode == http.StatusOK || statusCode == http.StatusPartialContent || statusCode == http.StatusNotModified
+}
+
+// PingURL проверяет доступность HTTP-сервиса: любой ответ, кроме 5xx, считается признаком того, что сервис доступен.
+func PingURL(ctx context.Context, client *http.Client, url string) (err error) {
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodHead, url, nil); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return
+}
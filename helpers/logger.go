@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"strings"
@@ -11,10 +12,10 @@ import (
 
 func SetupLogger(level slog.Level) {
 
-	logger := slog.New(tint.NewHandler(os.Stdout, &tint.Options{
+	logger := slog.New(NewContextHandler(tint.NewHandler(os.Stdout, &tint.Options{
 		Level:      level,
 		TimeFormat: time.StampMilli,
-	}))
+	})))
 	slog.SetDefault(logger)
 }
 
@@ -34,3 +35,38 @@ func ParseLogLevel(levelStr string) (level slog.Level) {
 	}
 	return
 }
+
+// contextHandler добавляет к записям лога значения из контекста запроса.
+type contextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler оборачивает обработчик slog так, что записи, созданные через *Context-методы
+// во время обработки запроса, получают его идентификатор (request_id).
+func NewContextHandler(next slog.Handler) (handler slog.Handler) {
+
+	return &contextHandler{next: next}
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) (enabled bool) {
+
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) (err error) {
+
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String(LogKeyRequestID, requestID))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) (handler slog.Handler) {
+
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) (handler slog.Handler) {
+
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
@@ -1,27 +1,46 @@
 package helpers
 
 const (
-	LogKeyError          = "error"
-	LogKeyDuration       = "duration"
-	LogKeyMethod         = "method"
-	LogKeyPath           = "path"
-	LogKeyStatusCode     = "status_code"
-	LogKeyAlias          = "alias"
-	LogKeyVersion        = "version"
-	LogKeyFilename       = "filename"
-	LogKeySource         = "source"
-	LogKeyRepoURL        = "repo_url"
-	LogKeyDescription    = "description"
-	LogKeyLimit          = "limit"
-	LogKeyOffset         = "offset"
-	LogKeyTotal          = "total"
-	LogKeySourceURL      = "source_url"
-	LogKeyVersionsCount  = "versions_count"
-	LogKeyRequestURL     = "request_url"
-	LogKeyEncryptionType = "encryption_type"
-	LogKeyAuthProvider   = "auth_provider"
-	LogKeyTokenMasked    = "token_masked"
-	LogKeyAction         = "action"
+	LogKeyError           = "error"
+	LogKeyDuration        = "duration"
+	LogKeyMethod          = "method"
+	LogKeyPath            = "path"
+	LogKeyStatusCode      = "status_code"
+	LogKeyAlias           = "alias"
+	LogKeyVersion         = "version"
+	LogKeyFilename        = "filename"
+	LogKeySource          = "source"
+	LogKeyRepoURL         = "repo_url"
+	LogKeyDescription     = "description"
+	LogKeyLimit           = "limit"
+	LogKeyOffset          = "offset"
+	LogKeyTotal           = "total"
+	LogKeySourceURL       = "source_url"
+	LogKeyVersionsCount   = "versions_count"
+	LogKeyRequestURL      = "request_url"
+	LogKeyEncryptionType  = "encryption_type"
+	LogKeyAuthProvider    = "auth_provider"
+	LogKeyTokenMasked     = "token_masked"
+	LogKeyAction          = "action"
+	LogKeyResolvedVersion = "resolved_version"
+	LogKeyDigest          = "digest"
+	LogKeySize            = "size"
+	LogKeyChecksum        = "checksum"
+	LogKeyInvalidation    = "invalidation"
+	LogKeyComponent       = "component"
+	LogKeyRequestID       = "request_id"
+	LogKeyRoute           = "route"
+	LogKeyBytes           = "bytes"
+	LogKeyPrincipal       = "principal"
+	LogKeyRouter          = "router"
+	LogKeyHost            = "host"
+	LogKeyAttempt         = "attempt"
+	LogKeyRetryDelay      = "retry_delay"
+	LogKeyCircuitState    = "circuit_state"
+	LogKeyRateLimitReset  = "rate_limit_reset"
+	LogKeyInstallation    = "installation_id"
+	LogKeyOwner           = "owner"
+	LogKeyRepo            = "repo"
 )
 
 const (
@@ -38,4 +57,6 @@ const (
 	ActionDeleteProject         = "delete_project"
 	ActionListProjects          = "list_projects"
 	ActionResolveProject        = "resolve_project"
+	ActionResolveVersion        = "resolve_version"
+	ActionInvalidateCache       = "invalidate_cache"
 )
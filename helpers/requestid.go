@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте; логгер из SetupLogger добавляет его к каждой записи.
+func WithRequestID(ctx context.Context, requestID string) (requestCtx context.Context) {
+
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) (requestID string) {
+
+	requestID, _ = ctx.Value(requestIDKey{}).(string)
+	return
+}
+
+// RequestID возвращает идентификатор из заголовка клиента, если он пригоден для логов, иначе новый UUID.
+func RequestID(header string) (requestID string) {
+
+	if header == "" || len(header) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for i := 0; i < len(header); i++ {
+		// только печатные ASCII-символы: идентификатор попадает в логи и заголовки ответа
+		if header[i] < 0x21 || header[i] > 0x7e {
+			return uuid.NewString()
+		}
+	}
+	return header
+}
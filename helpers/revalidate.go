@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+// ResponseStore хранит ответы источников вместе с ETag и Last-Modified; реализуется кешами cache/memory, cache/redis и cache/tiered.
+type ResponseStore interface {
+	GetUpstreamResponse(ctx context.Context, key string) (resp domain.UpstreamResponse, found bool, err error)
+	SetUpstreamResponse(ctx context.Context, key string, resp domain.UpstreamResponse, ttl time.Duration) (err error)
+}
+
+// Revalidate выполняет запрос через do и читает тело ответа. Если в store есть ответ на тот же запрос, отправляются
+// If-None-Match и If-Modified-Since, а на 304 возвращается сохранённое тело со статусом 200. Ответ 200 с валидаторами
+// сохраняется на ttl. Ошибки store не прерывают запрос; при store == nil запрос выполняется как есть.
+func Revalidate(req *http.Request, store ResponseStore, ttl time.Duration, do func(req *http.Request) (resp *http.Response, err error)) (statusCode int, body []byte, err error) {
+
+	ctx := req.Context()
+	key := req.Header.Get("Accept") + " " + req.URL.String()
+
+	var cached domain.UpstreamResponse
+	var found bool
+	if store != nil {
+		var getErr error
+		if cached, found, getErr = store.GetUpstreamResponse(ctx, key); getErr != nil {
+			slog.DebugContext(ctx, "Failed to get upstream response from cache",
+				slog.String(LogKeyRequestURL, req.URL.String()),
+				slog.Any(LogKeyError, getErr),
+			)
+		}
+	}
+	if found {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	var resp *http.Response
+	if resp, err = do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		slog.DebugContext(ctx, "Upstream response not modified",
+			slog.String(LogKeyRequestURL, req.URL.String()),
+		)
+		storeUpstreamResponse(ctx, store, key, cached, ttl)
+		return http.StatusOK, cached.Body, nil
+	}
+
+	statusCode = resp.StatusCode
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+
+	if store == nil || statusCode != http.StatusOK {
+		return
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag != "" || lastModified != "" {
+		storeUpstreamResponse(ctx, store, key, domain.UpstreamResponse{Body: body, ETag: etag, LastModified: lastModified}, ttl)
+	}
+	return
+}
+
+func storeUpstreamResponse(ctx context.Context, store ResponseStore, key string, resp domain.UpstreamResponse, ttl time.Duration) {
+
+	if err := store.SetUpstreamResponse(ctx, key, resp, ttl); err != nil {
+		slog.DebugContext(ctx, "Failed to set upstream response in cache",
+			slog.Any(LogKeyError, err),
+		)
+	}
+}
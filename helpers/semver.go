@@ -0,0 +1,264 @@
+package helpers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	VersionLatest       = "latest"
+	VersionLatestStable = "latest-stable"
+)
+
+// Version — разобранная семантическая версия. Префикс "v" и build-метаданные (+...) отбрасываются,
+// недостающие minor/patch считаются нулями (тег v1.2 трактуется как 1.2.0).
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+}
+
+func ParseVersion(version string) (v Version, ok bool) {
+
+	v, _, ok = parsePartialVersion(version, false)
+	return
+}
+
+func (v Version) IsPrerelease() (prerelease bool) {
+
+	return len(v.Prerelease) > 0
+}
+
+func (v Version) String() (s string) {
+
+	s = strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return
+}
+
+// CompareVersions сравнивает версии по правилам приоритета semver 2.0.0: -1, 0 или 1.
+func CompareVersions(a Version, b Version) (result int) {
+
+	if result = compareUint(a.Major, b.Major); result != 0 {
+		return
+	}
+	if result = compareUint(a.Minor, b.Minor); result != 0 {
+		return
+	}
+	if result = compareUint(a.Patch, b.Patch); result != 0 {
+		return
+	}
+
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		if result = comparePrereleaseIdent(a.Prerelease[i], b.Prerelease[i]); result != 0 {
+			return
+		}
+	}
+	return compareUint(uint64(len(a.Prerelease)), uint64(len(b.Prerelease)))
+}
+
+// SortVersions сортирует теги на месте: сначала semver по убыванию приоритета, затем не-semver теги в обратном лексикографическом порядке.
+func SortVersions(versions []string) {
+
+	parsed := make(map[string]Version, len(versions))
+	for _, tag := range versions {
+		if v, ok := ParseVersion(tag); ok {
+			parsed[tag] = v
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, okI := parsed[versions[i]]
+		vj, okJ := parsed[versions[j]]
+		switch {
+		case okI && okJ:
+			if cmp := CompareVersions(vi, vj); cmp != 0 {
+				return cmp > 0
+			}
+			return versions[i] > versions[j]
+		case okI:
+			return true
+		case okJ:
+			return false
+		default:
+			return versions[i] > versions[j]
+		}
+	})
+}
+
+// IsPrereleaseVersion — true для semver-тегов с пререлизной частью; не-semver теги пререлизами не считаются.
+func IsPrereleaseVersion(version string) (prerelease bool) {
+
+	v, ok := ParseVersion(version)
+	return ok && v.IsPrerelease()
+}
+
+// FilterVersions оставляет теги, подходящие под ограничение (например ">=1.2 <2"), сохраняя порядок.
+// Не-semver теги под непустое ограничение не подпадают.
+func FilterVersions(versions []string, includePrerelease bool, constraint string) (filtered []string, err error) {
+
+	var c VersionConstraint
+	hasConstraint := strings.TrimSpace(constraint) != ""
+	if hasConstraint {
+		if c, err = ParseConstraint(constraint); err != nil {
+			return
+		}
+	}
+
+	filtered = make([]string, 0, len(versions))
+	for _, tag := range versions {
+		if !includePrerelease && IsPrereleaseVersion(tag) {
+			continue
+		}
+		if hasConstraint && !c.Check(tag) {
+			continue
+		}
+		filtered = append(filtered, tag)
+	}
+	return
+}
+
+// ResolveVersion выбирает конкретный тег из списка, отсортированного SortVersions: точное совпадение,
+// latest (старшая версия), latest-stable (старшая версия без пререлиза) или старшая версия, подходящая под ограничение (^1.4, ~2.0).
+func ResolveVersion(versions []string, requested string) (resolved string, found bool) {
+
+	for _, tag := range versions {
+		if tag == requested {
+			return tag, true
+		}
+	}
+
+	switch requested {
+	case VersionLatest:
+		if len(versions) > 0 {
+			return versions[0], true
+		}
+		return
+	case VersionLatestStable:
+		for _, tag := range versions {
+			if !IsPrereleaseVersion(tag) {
+				return tag, true
+			}
+		}
+		return
+	}
+
+	c, err := ParseConstraint(requested)
+	if err != nil {
+		return
+	}
+	for _, tag := range versions {
+		if c.Check(tag) {
+			return tag, true
+		}
+	}
+	return
+}
+
+func parsePartialVersion(version string, allowWildcards bool) (v Version, specified int, ok bool) {
+
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	if idx := strings.IndexByte(version, '+'); idx >= 0 {
+		version = version[:idx]
+	}
+	if version == "" {
+		return
+	}
+
+	core := version
+	if idx := strings.IndexByte(version, '-'); idx >= 0 {
+		core = version[:idx]
+		pre := version[idx+1:]
+		if pre == "" {
+			return
+		}
+		v.Prerelease = strings.Split(pre, ".")
+		for _, ident := range v.Prerelease {
+			if ident == "" || !isPrereleaseIdent(ident) {
+				return
+			}
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return
+	}
+
+	numbers := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	wildcard := false
+	for i, part := range parts {
+		if allowWildcards && (part == "x" || part == "X" || part == "*") {
+			wildcard = true
+			continue
+		}
+		if wildcard || part == "" {
+			return
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return
+		}
+		*numbers[i] = n
+		specified++
+	}
+
+	if specified < 3 && len(v.Prerelease) > 0 {
+		return
+	}
+
+	ok = true
+	return
+}
+
+func isPrereleaseIdent(ident string) (ok bool) {
+
+	for _, r := range ident {
+		if !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func comparePrereleaseIdent(a string, b string) (result int) {
+
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareUint(a uint64, b uint64) (result int) {
+
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
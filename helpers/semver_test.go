@@ -0,0 +1,158 @@
+package helpers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+
+	tests := []struct {
+		version string
+		want    string
+		ok      bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"v1.2", "1.2.0", true},
+		{"V2", "2.0.0", true},
+		{"1.2.3+build.7", "1.2.3", true},
+		{"1.2.3-rc.1+build", "1.2.3-rc.1", true},
+		{"1.2-rc", "", false},
+		{"1.2.3-", "", false},
+		{"1.2.3-rc..1", "", false},
+		{"1.x", "", false},
+		{"1.2.3.4", "", false},
+		{"main", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		v, ok := ParseVersion(tt.version)
+		if ok != tt.ok {
+			t.Errorf("ParseVersion(%q) ok = %v, want %v", tt.version, ok, tt.ok)
+			continue
+		}
+		if ok && v.String() != tt.want {
+			t.Errorf("ParseVersion(%q) = %s, want %s", tt.version, v, tt.want)
+		}
+	}
+}
+
+func TestCompareVersionsPrecedence(t *testing.T) {
+
+	// порядок из спецификации semver 2.0.0, по возрастанию
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i := 0; i+1 < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i])
+		b, _ := ParseVersion(ordered[i+1])
+		if got := CompareVersions(a, b); got != -1 {
+			t.Errorf("CompareVersions(%s, %s) = %d, want -1", ordered[i], ordered[i+1], got)
+		}
+		if got := CompareVersions(b, a); got != 1 {
+			t.Errorf("CompareVersions(%s, %s) = %d, want 1", ordered[i+1], ordered[i], got)
+		}
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		versions []string
+		want     []string
+	}{
+		{
+			name:     "semver by precedence",
+			versions: []string{"v1.2.0", "v1.10.0", "v2.0.0-rc.1", "v2.0.0", "v1.2.0-beta"},
+			want:     []string{"v2.0.0", "v2.0.0-rc.1", "v1.10.0", "v1.2.0", "v1.2.0-beta"},
+		},
+		{
+			name:     "equal versions by tag",
+			versions: []string{"1.2.0", "v1.2.0", "v1.2"},
+			want:     []string{"v1.2.0", "v1.2", "1.2.0"},
+		},
+		{
+			name:     "non-semver after semver in reverse lexicographic order",
+			versions: []string{"main", "v0.1.0", "nightly", "build-10", "build-9"},
+			want:     []string{"v0.1.0", "nightly", "main", "build-9", "build-10"},
+		},
+	}
+
+	for _, tt := range tests {
+		versions := slices.Clone(tt.versions)
+		SortVersions(versions)
+		if !slices.Equal(versions, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, versions, tt.want)
+		}
+	}
+}
+
+func TestFilterVersions(t *testing.T) {
+
+	versions := []string{"v2.0.0-rc.1", "v1.3.0", "v1.2.0", "v1.2.0-beta", "main"}
+
+	tests := []struct {
+		includePrerelease bool
+		constraint        string
+		want              []string
+	}{
+		{true, "", versions},
+		{false, "", []string{"v1.3.0", "v1.2.0", "main"}},
+		{true, "^1.2", []string{"v1.3.0", "v1.2.0"}},
+		{true, ">=1.2.0-alpha", []string{"v1.3.0", "v1.2.0", "v1.2.0-beta"}},
+		{false, ">=1.2.0-alpha", []string{"v1.3.0", "v1.2.0"}},
+		{true, ">=2.0.0-rc.1", []string{"v2.0.0-rc.1"}},
+	}
+
+	for _, tt := range tests {
+		got, err := FilterVersions(versions, tt.includePrerelease, tt.constraint)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.constraint, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FilterVersions(prerelease=%v, %q) = %v, want %v", tt.includePrerelease, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+
+	versions := []string{"v2.0.0-rc.1", "v1.4.2", "v1.4.0", "v1.3.9", "main"}
+
+	tests := []struct {
+		requested string
+		want      string
+		found     bool
+	}{
+		{VersionLatest, "v2.0.0-rc.1", true},
+		{VersionLatestStable, "v1.4.2", true},
+		{"main", "main", true},
+		{"v1.4.0", "v1.4.0", true},
+		{"^1.3", "v1.4.2", true},
+		{"~1.3", "v1.3.9", true},
+		{"^2", "", false},
+		{"^3", "", false},
+		{"v9.9.9", "", false},
+	}
+
+	for _, tt := range tests {
+		resolved, found := ResolveVersion(versions, tt.requested)
+		if resolved != tt.want || found != tt.found {
+			t.Errorf("ResolveVersion(%q) = %q, %v, want %q, %v", tt.requested, resolved, found, tt.want, tt.found)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package tgproxy
 
 import (
 	"encoding/json"
-	"io"
+	"errors"
 	"log/slog"
 	"net/http"
 	"path"
@@ -10,9 +10,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/seniorGolang/tg-proxy/core"
+	"github.com/seniorGolang/tg-proxy/errs"
 	"github.com/seniorGolang/tg-proxy/helpers"
-	"github.com/seniorGolang/tg-proxy/model/domain"
 	"github.com/seniorGolang/tg-proxy/model/dto"
 )
 
@@ -23,7 +22,9 @@ func (p *Proxy) SetPublicRoutes(mux *http.ServeMux, prefix string) {
 		base = "/"
 	}
 	p.publicPrefix = base
-	h := p.publicAuthMiddleware
+	h := func(next http.HandlerFunc) (handler http.HandlerFunc) {
+		return p.requestMiddleware(p.metricsMiddleware(p.tracingMiddleware(p.publicAuthMiddleware(p.staleWarningMiddleware(next)))))
+	}
 
 	mux.HandleFunc("GET "+base, h(func(w http.ResponseWriter, r *http.Request) {
 		p.handleGetAggregateManifestNetHTTP(w, r)
@@ -54,7 +55,9 @@ func (p *Proxy) SetAdminRoutes(mux *http.ServeMux, prefix string) {
 	if base == "" {
 		base = "/"
 	}
-	h := p.adminAuthMiddleware
+	h := func(next http.HandlerFunc) (handler http.HandlerFunc) {
+		return p.requestMiddleware(p.metricsMiddleware(p.tracingMiddleware(p.adminAuthMiddleware(next))))
+	}
 
 	mux.HandleFunc("GET "+path.Join(base, "projects"), h(func(w http.ResponseWriter, r *http.Request) {
 		p.handleListProjectsNetHTTP(w, r)
@@ -83,9 +86,9 @@ func (p *Proxy) handleGetManifestNetHTTP(w http.ResponseWriter, r *http.Request,
 
 	startTime := time.Now()
 
-	manifest, statusCode, err := p.handleGetManifest(r.Context(), alias, version)
+	manifest, resolved, etag, statusCode, err := p.handleGetManifest(r.Context(), alias, version, r.Header.Get("If-None-Match"))
 	if err != nil {
-		slog.Error("Failed to get manifest",
+		slog.ErrorContext(r.Context(), "Failed to get manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -95,14 +98,15 @@ func (p *Proxy) handleGetManifestNetHTTP(w http.ResponseWriter, r *http.Request,
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Manifest request completed",
+	slog.InfoContext(r.Context(), "Manifest request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyResolvedVersion, resolved),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
 		slog.String(helpers.LogKeyMethod, r.Method),
 		slog.String(helpers.LogKeyPath, r.URL.Path),
@@ -111,7 +115,13 @@ func (p *Proxy) handleGetManifestNetHTTP(w http.ResponseWriter, r *http.Request,
 	)
 
 	w.Header().Set("Content-Type", "application/x-yaml")
+	w.Header().Set(headerResolvedVersion, resolved)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", manifestCacheControl(version, resolved))
 	w.WriteHeader(statusCode)
+	if statusCode == http.StatusNotModified {
+		return
+	}
 	_, _ = w.Write(manifest)
 }
 
@@ -119,9 +129,9 @@ func (p *Proxy) handleGetAggregateManifestNetHTTP(w http.ResponseWriter, r *http
 
 	startTime := time.Now()
 
-	manifest, statusCode, err := p.handleGetAggregateManifest(r.Context())
+	manifest, etag, statusCode, err := p.handleGetAggregateManifest(r.Context(), r.Header.Get("If-None-Match"))
 	if err != nil {
-		slog.Error("Failed to get aggregate manifest",
+		slog.ErrorContext(r.Context(), "Failed to get aggregate manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, r.Method),
@@ -129,11 +139,11 @@ func (p *Proxy) handleGetAggregateManifestNetHTTP(w http.ResponseWriter, r *http
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Aggregate manifest request completed",
+	slog.InfoContext(r.Context(), "Aggregate manifest request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
 		slog.String(helpers.LogKeyMethod, r.Method),
@@ -143,7 +153,12 @@ func (p *Proxy) handleGetAggregateManifestNetHTTP(w http.ResponseWriter, r *http
 	)
 
 	w.Header().Set("Content-Type", "application/x-yaml")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControlAggregate)
 	w.WriteHeader(statusCode)
+	if statusCode == http.StatusNotModified {
+		return
+	}
 	_, _ = w.Write(manifest)
 }
 
@@ -153,7 +168,7 @@ func (p *Proxy) handleGetAggregateManifestAtVersionNetHTTP(w http.ResponseWriter
 
 	currentVersion, statusCode, err := p.handleGetCatalogVersion(r.Context())
 	if err != nil {
-		slog.Error("Failed to get catalog version",
+		slog.ErrorContext(r.Context(), "Failed to get catalog version",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, r.Method),
@@ -161,17 +176,17 @@ func (p *Proxy) handleGetAggregateManifestAtVersionNetHTTP(w http.ResponseWriter
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 	if requestedVersion != currentVersion {
-		http.Error(w, "Not found", http.StatusNotFound)
+		writeErrorNetHTTP(w, r, http.StatusNotFound, errs.ErrVersionNotFound)
 		return
 	}
 
-	manifest, statusCode, err := p.handleGetAggregateManifest(r.Context())
+	manifest, etag, statusCode, err := p.handleGetAggregateManifest(r.Context(), r.Header.Get("If-None-Match"))
 	if err != nil {
-		slog.Error("Failed to get aggregate manifest",
+		slog.ErrorContext(r.Context(), "Failed to get aggregate manifest",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, r.Method),
@@ -179,11 +194,11 @@ func (p *Proxy) handleGetAggregateManifestAtVersionNetHTTP(w http.ResponseWriter
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Aggregate manifest request completed",
+	slog.InfoContext(r.Context(), "Aggregate manifest request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetAggregateManifest),
 		slog.String(helpers.LogKeyVersion, currentVersion),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -194,7 +209,12 @@ func (p *Proxy) handleGetAggregateManifestAtVersionNetHTTP(w http.ResponseWriter
 	)
 
 	w.Header().Set("Content-Type", "application/x-yaml")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControlAggregate)
 	w.WriteHeader(statusCode)
+	if statusCode == http.StatusNotModified {
+		return
+	}
 	_, _ = w.Write(manifest)
 }
 
@@ -204,7 +224,7 @@ func (p *Proxy) handleGetCatalogVersionNetHTTP(w http.ResponseWriter, r *http.Re
 
 	version, statusCode, err := p.handleGetCatalogVersion(r.Context())
 	if err != nil {
-		slog.Error("Failed to get catalog version",
+		slog.ErrorContext(r.Context(), "Failed to get catalog version",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetCatalogVersion),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyMethod, r.Method),
@@ -212,11 +232,11 @@ func (p *Proxy) handleGetCatalogVersionNetHTTP(w http.ResponseWriter, r *http.Re
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Catalog version request completed",
+	slog.InfoContext(r.Context(), "Catalog version request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetCatalogVersion),
 		slog.String(helpers.LogKeyVersion, version),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -233,10 +253,11 @@ func (p *Proxy) handleGetCatalogVersionNetHTTP(w http.ResponseWriter, r *http.Re
 func (p *Proxy) handleGetFileNetHTTP(w http.ResponseWriter, r *http.Request, alias string, version string, filename string) {
 
 	startTime := time.Now()
+	req := newFileRequestNetHTTP(r)
 
-	stream, statusCode, err := p.handleGetFile(r.Context(), alias, version, filename)
+	file, statusCode, err := p.handleGetFile(r.Context(), alias, version, filename, req)
 	if err != nil {
-		slog.Error("Failed to get file",
+		slog.ErrorContext(r.Context(), "Failed to get file",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -247,84 +268,59 @@ func (p *Proxy) handleGetFileNetHTTP(w http.ResponseWriter, r *http.Request, ali
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
-	defer stream.Close()
+	defer file.Body.Close()
 
-	var project domain.Project
-	var found bool
-	if project, found, _ = p.engine.GetProject(r.Context(), alias); !found {
-		slog.Debug("Project not found during file streaming",
+	var sent bool
+	if statusCode, sent, err = p.serveFile(netHTTPFileWriter{w: w}, req, alias, file, version); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to stream file",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyResolvedVersion, file.Version),
 			slog.String(helpers.LogKeyFilename, filename),
-		)
-		http.Error(w, "Project not found", http.StatusNotFound)
-		return
-	}
-
-	var src core.Source
-	if src, err = p.engine.GetSource(project.SourceName); err != nil {
-		slog.Error("Source not found",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeySource, project.SourceName),
-			slog.Any(helpers.LogKeyError, err),
-		)
-		http.Error(w, "Source not found", http.StatusInternalServerError)
-		return
-	}
-
-	slog.Debug("Fetching file from source",
-		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-		slog.String(helpers.LogKeyAlias, alias),
-		slog.String(helpers.LogKeyVersion, version),
-		slog.String(helpers.LogKeyFilename, filename),
-		slog.String(helpers.LogKeySource, project.SourceName),
-	)
-
-	var resp *http.Response
-	if resp, err = src.GetFileResponse(r.Context(), project, version, filename); err != nil {
-		slog.Error("Failed to fetch file from source",
-			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
-			slog.String(helpers.LogKeyAlias, alias),
-			slog.String(helpers.LogKeyVersion, version),
-			slog.String(helpers.LogKeyFilename, filename),
-			slog.String(helpers.LogKeySource, project.SourceName),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
+			slog.String(helpers.LogKeyMethod, r.Method),
+			slog.String(helpers.LogKeyPath, r.URL.Path),
+			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, "Failed to fetch file", http.StatusBadGateway)
+		if !sent {
+			writeErrorNetHTTP(w, r, statusCode, err)
+			return
+		}
+		if errors.Is(err, errs.ErrChecksumMismatch) {
+			// заголовки уже отправлены — обрываем соединение, чтобы клиент не принял файл за целый
+			panic(http.ErrAbortHandler)
+		}
 		return
 	}
-	defer resp.Body.Close()
 
-	slog.Info("File request completed",
+	slog.InfoContext(r.Context(), "File request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyResolvedVersion, file.Version),
 		slog.String(helpers.LogKeyFilename, filename),
-		slog.Int(helpers.LogKeyStatusCode, resp.StatusCode),
+		slog.Int(helpers.LogKeyStatusCode, statusCode),
 		slog.String(helpers.LogKeyMethod, r.Method),
 		slog.String(helpers.LogKeyPath, r.URL.Path),
 		slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
-		slog.String("content_type", resp.Header.Get("Content-Type")),
-		slog.String("content_length", resp.Header.Get("Content-Length")),
+		slog.String("content_type", file.ContentType),
+		slog.Int64("content_length", file.ContentLength),
+		slog.Bool("cached", file.Cached),
 	)
-
-	p.copyResponseHeadersNetHTTP(w, resp)
-
-	_, _ = io.Copy(w, resp.Body)
 }
 
 func (p *Proxy) handleGetVersionsNetHTTP(w http.ResponseWriter, r *http.Request, alias string) {
 
 	startTime := time.Now()
 
-	versions, statusCode, err := p.handleGetVersions(r.Context(), alias)
+	versions, statusCode, err := p.handleGetVersions(r.Context(), alias, r.URL.Query().Get("prerelease"), r.URL.Query().Get("constraint"))
 	if err != nil {
-		slog.Error("Failed to get versions",
+		slog.ErrorContext(r.Context(), "Failed to get versions",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -333,11 +329,11 @@ func (p *Proxy) handleGetVersionsNetHTTP(w http.ResponseWriter, r *http.Request,
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Versions request completed",
+	slog.InfoContext(r.Context(), "Versions request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -372,7 +368,7 @@ func (p *Proxy) handleListProjectsNetHTTP(w http.ResponseWriter, r *http.Request
 
 	projects, total, statusCode, err := p.handleListProjects(r.Context(), limit, offset)
 	if err != nil {
-		slog.Error("Failed to list projects",
+		slog.ErrorContext(r.Context(), "Failed to list projects",
 			slog.String(helpers.LogKeyAction, helpers.ActionListProjects),
 			slog.Int(helpers.LogKeyLimit, limit),
 			slog.Int(helpers.LogKeyOffset, offset),
@@ -382,11 +378,11 @@ func (p *Proxy) handleListProjectsNetHTTP(w http.ResponseWriter, r *http.Request
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("List projects request completed",
+	slog.InfoContext(r.Context(), "List projects request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionListProjects),
 		slog.Int(helpers.LogKeyLimit, limit),
 		slog.Int(helpers.LogKeyOffset, offset),
@@ -412,31 +408,31 @@ func (p *Proxy) handleCreateProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 
 	var req dto.ProjectCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Debug("Invalid request body",
+		slog.DebugContext(r.Context(), "Invalid request body",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
 	if err := helpers.ValidateStruct(&req); err != nil {
-		slog.Debug("Validation failed",
+		slog.DebugContext(r.Context(), "Validation failed",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyAlias, req.Alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeValidationFailed, err.Error())
 		return
 	}
 
 	statusCode, id, err := p.handleCreateProject(r.Context(), req)
 	if err != nil {
-		slog.Error("Failed to create project",
+		slog.ErrorContext(r.Context(), "Failed to create project",
 			slog.String(helpers.LogKeyAction, helpers.ActionCreateProject),
 			slog.String(helpers.LogKeyAlias, req.Alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -445,7 +441,7 @@ func (p *Proxy) handleCreateProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
@@ -463,7 +459,7 @@ func (p *Proxy) handleCreateProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 	if req.RepoURL != "" {
 		args = append(args, slog.String(helpers.LogKeyRepoURL, req.RepoURL))
 	}
-	slog.Info("Create project request completed", args...)
+	slog.InfoContext(r.Context(), "Create project request completed", args...)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -475,20 +471,20 @@ func (p *Proxy) handleGetProjectNetHTTP(w http.ResponseWriter, r *http.Request,
 	startTime := time.Now()
 
 	if err := helpers.ValidateAlias(alias); err != nil {
-		slog.Debug("Invalid alias",
+		slog.DebugContext(r.Context(), "Invalid alias",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeInvalidAlias, err.Error())
 		return
 	}
 
 	project, found, statusCode, err := p.handleGetProject(r.Context(), alias)
 	if err != nil {
-		slog.Error("Failed to get project",
+		slog.ErrorContext(r.Context(), "Failed to get project",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -497,17 +493,17 @@ func (p *Proxy) handleGetProjectNetHTTP(w http.ResponseWriter, r *http.Request,
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 	if !found {
-		slog.Debug("Project not found",
+		slog.DebugContext(r.Context(), "Project not found",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 		)
-		http.Error(w, "Project not found", http.StatusNotFound)
+		writeErrorNetHTTP(w, r, http.StatusNotFound, errs.ErrProjectNotFound)
 		return
 	}
 
@@ -525,7 +521,7 @@ func (p *Proxy) handleGetProjectNetHTTP(w http.ResponseWriter, r *http.Request,
 	if project.RepoURL != "" {
 		args = append(args, slog.String(helpers.LogKeyRepoURL, project.RepoURL))
 	}
-	slog.Info("Get project request completed", args...)
+	slog.InfoContext(r.Context(), "Get project request completed", args...)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -537,45 +533,45 @@ func (p *Proxy) handleUpdateProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 	startTime := time.Now()
 
 	if err := helpers.ValidateAlias(alias); err != nil {
-		slog.Debug("Invalid alias",
+		slog.DebugContext(r.Context(), "Invalid alias",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeInvalidAlias, err.Error())
 		return
 	}
 
 	var req dto.ProjectUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Debug("Invalid request body",
+		slog.DebugContext(r.Context(), "Invalid request body",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
 	if err := helpers.ValidateStruct(&req); err != nil {
-		slog.Debug("Validation failed",
+		slog.DebugContext(r.Context(), "Validation failed",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeValidationFailed, err.Error())
 		return
 	}
 
 	statusCode, err := p.handleUpdateProject(r.Context(), alias, req)
 	if err != nil {
-		slog.Error("Failed to update project",
+		slog.ErrorContext(r.Context(), "Failed to update project",
 			slog.String(helpers.LogKeyAction, helpers.ActionUpdateProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -584,7 +580,7 @@ func (p *Proxy) handleUpdateProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
@@ -608,7 +604,7 @@ func (p *Proxy) handleUpdateProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 	if req.Description != nil {
 		args = append(args, slog.String(helpers.LogKeyDescription, *req.Description))
 	}
-	slog.Info("Update project request completed", args...)
+	slog.InfoContext(r.Context(), "Update project request completed", args...)
 
 	w.WriteHeader(statusCode)
 }
@@ -618,20 +614,20 @@ func (p *Proxy) handleDeleteProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 	startTime := time.Now()
 
 	if err := helpers.ValidateAlias(alias); err != nil {
-		slog.Debug("Invalid alias",
+		slog.DebugContext(r.Context(), "Invalid alias",
 			slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyMethod, r.Method),
 			slog.String(helpers.LogKeyPath, r.URL.Path),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblemNetHTTP(w, r, http.StatusBadRequest, codeInvalidAlias, err.Error())
 		return
 	}
 
 	statusCode, err := p.handleDeleteProject(r.Context(), alias)
 	if err != nil {
-		slog.Error("Failed to delete project",
+		slog.ErrorContext(r.Context(), "Failed to delete project",
 			slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -640,11 +636,11 @@ func (p *Proxy) handleDeleteProjectNetHTTP(w http.ResponseWriter, r *http.Reques
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Delete project request completed",
+	slog.InfoContext(r.Context(), "Delete project request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionDeleteProject),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.Int(helpers.LogKeyStatusCode, statusCode),
@@ -676,7 +672,7 @@ func (p *Proxy) handleGetManifestDataNetHTTP(w http.ResponseWriter, r *http.Requ
 
 	manifest, statusCode, err := p.handleGetManifestData(r.Context(), alias, version)
 	if err != nil {
-		slog.Error("Failed to get manifest data",
+		slog.ErrorContext(r.Context(), "Failed to get manifest data",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifestData),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -686,11 +682,11 @@ func (p *Proxy) handleGetManifestDataNetHTTP(w http.ResponseWriter, r *http.Requ
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Manifest data request completed",
+	slog.InfoContext(r.Context(), "Manifest data request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifestData),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
@@ -711,7 +707,7 @@ func (p *Proxy) handleGetManifestAggregatedNetHTTP(w http.ResponseWriter, r *htt
 
 	out, statusCode, err := p.handleGetManifestAggregated(r.Context(), alias, version)
 	if err != nil {
-		slog.Error("Failed to get manifest aggregated",
+		slog.ErrorContext(r.Context(), "Failed to get manifest aggregated",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifestAggregated),
 			slog.String(helpers.LogKeyAlias, alias),
 			slog.String(helpers.LogKeyVersion, version),
@@ -721,11 +717,11 @@ func (p *Proxy) handleGetManifestAggregatedNetHTTP(w http.ResponseWriter, r *htt
 			slog.Duration(helpers.LogKeyDuration, time.Since(startTime)),
 			slog.Any(helpers.LogKeyError, err),
 		)
-		http.Error(w, helpers.GetErrorMessage(err), statusCode)
+		writeErrorNetHTTP(w, r, statusCode, err)
 		return
 	}
 
-	slog.Info("Manifest aggregated request completed",
+	slog.InfoContext(r.Context(), "Manifest aggregated request completed",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifestAggregated),
 		slog.String(helpers.LogKeyAlias, alias),
 		slog.String(helpers.LogKeyVersion, version),
@@ -740,18 +736,3 @@ func (p *Proxy) handleGetManifestAggregatedNetHTTP(w http.ResponseWriter, r *htt
 	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(out)
 }
-
-func (p *Proxy) copyResponseHeadersNetHTTP(w http.ResponseWriter, resp *http.Response) {
-
-	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
-		w.Header().Set("Content-Type", contentType)
-	} else {
-		w.Header().Set("Content-Type", "application/octet-stream")
-	}
-
-	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
-		w.Header().Set("Content-Length", contentLength)
-	}
-
-	w.Header().Set("Cache-Control", "public, max-age=3600")
-}
@@ -0,0 +1,146 @@
+package tgproxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	routerNetHTTP = "net/http"
+	routerFiber   = "fiber"
+)
+
+const contentTypeMetrics = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsRecorder принимает наблюдения HTTP-слоя и отдаёт накопленные метрики в формате Prometheus.
+type metricsRecorder interface {
+	ObserveRequest(router string, route string, method string, statusCode int, duration time.Duration)
+	AddBytesStreamed(alias string, n int64)
+	SetCatalogVersion(version string)
+	WriteTo(w io.Writer) (n int64, err error)
+}
+
+// statusWriter запоминает статус и размер ответа для метрик, трассировки и журнала доступа.
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int64
+}
+
+// Metrics включает сбор метрик запросов (например, metrics.Metrics); отдаются через SetMetricsRoutes.
+func Metrics(m metricsRecorder) (opt ProxyOption) {
+	return func(p *Proxy) {
+		p.metrics = m
+	}
+}
+
+// SetMetricsRoutes монтирует эндпоинт метрик Prometheus по пути route (обычно /metrics). Авторизации нет:
+// эндпоинт стоит публиковать только во внутренней сети.
+func (p *Proxy) SetMetricsRoutes(mux *http.ServeMux, route string) {
+
+	mux.HandleFunc("GET "+route, func(w http.ResponseWriter, r *http.Request) {
+		if p.metrics == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeMetrics)
+		_, _ = p.metrics.WriteTo(w)
+	})
+}
+
+func (p *Proxy) SetMetricsRoutesFiber(app *fiber.App, route string) {
+
+	app.Get(route, func(c *fiber.Ctx) (err error) {
+		if p.metrics == nil {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		c.Set(fiber.HeaderContentType, contentTypeMetrics)
+		_, err = p.metrics.WriteTo(c.Response().BodyWriter())
+		return
+	})
+}
+
+// metricsMiddleware учитывает запрос по шаблону маршрута из ServeMux, а не по пути.
+func (p *Proxy) metricsMiddleware(next http.HandlerFunc) (handler http.HandlerFunc) {
+
+	if p.metrics == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		mw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		defer func() {
+			route := r.Pattern
+			if _, pattern, found := strings.Cut(route, " "); found {
+				route = pattern
+			}
+			p.metrics.ObserveRequest(routerNetHTTP, route, r.Method, mw.statusCode, time.Since(startTime))
+		}()
+		next(mw, r)
+	}
+}
+
+func (p *Proxy) metricsFiberMiddleware(c *fiber.Ctx) (err error) {
+
+	if p.metrics == nil {
+		return c.Next()
+	}
+
+	startTime := time.Now()
+	err = c.Next()
+
+	p.metrics.ObserveRequest(routerFiber, c.Route().Path, c.Method(), fiberStatusCode(c, err), time.Since(startTime))
+	return
+}
+
+func (p *Proxy) observeCatalogVersion(version string) {
+
+	if p.metrics != nil {
+		p.metrics.SetCatalogVersion(version)
+	}
+}
+
+func (p *Proxy) observeBytesStreamed(alias string, n int64) {
+
+	if p.metrics != nil {
+		p.metrics.AddBytesStreamed(alias, n)
+	}
+}
+
+// fiberStatusCode — статус ответа Fiber с учётом ошибки, которую обработчик вернул вместо ответа.
+func fiberStatusCode(c *fiber.Ctx, err error) (statusCode int) {
+
+	statusCode = c.Response().StatusCode()
+	if err != nil {
+		statusCode = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			statusCode = fiberErr.Code
+		}
+	}
+	return
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(p []byte) (n int, err error) {
+
+	n, err = w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return
+}
+
+func (w *statusWriter) Unwrap() (rw http.ResponseWriter) {
+
+	return w.ResponseWriter
+}
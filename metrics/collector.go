@@ -0,0 +1,215 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultBuckets — границы гистограмм длительности в секундах: от быстрых ответов из кеша до долгих загрузок файлов.
+var defaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type series struct {
+	labels []string
+	value  float64
+}
+
+// valueVec — счётчик или показатель с метками; значения хранятся по ключу из значений меток.
+type valueVec struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newCounterVec(name string, help string, labels ...string) (v *valueVec) {
+
+	return newValueVec(name, help, "counter", labels...)
+}
+
+func newGaugeVec(name string, help string, labels ...string) (v *valueVec) {
+
+	return newValueVec(name, help, "gauge", labels...)
+}
+
+func newValueVec(name string, help string, typ string, labels ...string) (v *valueVec) {
+
+	return &valueVec{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		series: make(map[string]*series),
+	}
+}
+
+func (v *valueVec) add(value float64, labels ...string) {
+
+	key := seriesKey(labels)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, exists := v.series[key]
+	if !exists {
+		s = &series{labels: labels}
+		v.series[key] = s
+	}
+	s.value += value
+}
+
+// replace оставляет единственную серию — так устроены info-метрики вроде версии каталога.
+func (v *valueVec) replace(value float64, labels ...string) {
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.series = map[string]*series{seriesKey(labels): {labels: labels, value: value}}
+}
+
+func (v *valueVec) write(w io.Writer) (err error) {
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.typ); err != nil {
+		return
+	}
+	for _, key := range sortedKeys(v.series) {
+		s := v.series[key]
+		if _, err = fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, s.labels), formatValue(s.value)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+func newHistogramVec(name string, help string, buckets []float64, labels ...string) (h *histogramVec) {
+
+	return &histogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+func (h *histogramVec) observe(value float64, labels ...string) {
+
+	key := seriesKey(labels)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, exists := h.series[key]
+	if !exists {
+		s = &histogramSeries{labels: labels, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *histogramVec) write(w io.Writer) (err error) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return
+	}
+	bucketLabels := append(append([]string{}, h.labels...), "le")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		for i, bound := range h.buckets {
+			labels := append(append([]string{}, s.labels...), formatValue(bound))
+			if _, err = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, labels), s.counts[i]); err != nil {
+				return
+			}
+		}
+		labels := append(append([]string{}, s.labels...), "+Inf")
+		if _, err = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, labels), s.count); err != nil {
+			return
+		}
+		if _, err = fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n",
+			h.name, formatLabels(h.labels, s.labels), formatValue(s.sum),
+			h.name, formatLabels(h.labels, s.labels), s.count); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func seriesKey(labels []string) (key string) {
+
+	return strings.Join(labels, "\x00")
+}
+
+func sortedKeys[T any](m map[string]T) (keys []string) {
+
+	keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func formatLabels(names []string, values []string) (out string) {
+
+	if len(names) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(value float64) (out string) {
+
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
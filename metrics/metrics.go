@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+// Metrics собирает метрики прокси, движка и источников и отдаёт их в текстовом формате Prometheus.
+// Один экземпляр передаётся в core.NewEngine (core.Metrics) и в tgproxy.New (tgproxy.Metrics).
+type Metrics struct {
+	requests         *valueVec
+	requestDuration  *histogramVec
+	upstreamRequests *valueVec
+	upstreamErrors   *valueVec
+	upstreamDuration *histogramVec
+	cacheRequests    *valueVec
+	bytesStreamed    *valueVec
+	catalogVersion   *valueVec
+}
+
+func New() (m *Metrics) {
+
+	return &Metrics{
+		requests: newCounterVec("tgproxy_http_requests_total",
+			"HTTP requests by router, route, method and status.", "router", "route", "method", "status"),
+		requestDuration: newHistogramVec("tgproxy_http_request_duration_seconds",
+			"HTTP request latency by router, route and method.", defaultBuckets, "router", "route", "method"),
+		upstreamRequests: newCounterVec("tgproxy_upstream_requests_total",
+			"Calls to package sources by source and operation.", "source", "operation"),
+		upstreamErrors: newCounterVec("tgproxy_upstream_errors_total",
+			"Failed calls to package sources by source and operation.", "source", "operation"),
+		upstreamDuration: newHistogramVec("tgproxy_upstream_request_duration_seconds",
+			"Latency of calls to package sources by source and operation.", defaultBuckets, "source", "operation"),
+		cacheRequests: newCounterVec("tgproxy_cache_requests_total",
+			"Cache lookups by cache kind and result (hit or miss).", "cache", "result"),
+		bytesStreamed: newCounterVec("tgproxy_file_bytes_streamed_total",
+			"Bytes of release files sent to clients by project.", "alias"),
+		catalogVersion: newGaugeVec("tgproxy_catalog_info",
+			"Current catalog version; the value is always 1.", "version"),
+	}
+}
+
+// ObserveRequest учитывает HTTP-запрос. route — шаблон маршрута, а не путь, чтобы число серий не росло с числом файлов.
+func (m *Metrics) ObserveRequest(router string, route string, method string, statusCode int, duration time.Duration) {
+
+	m.requests.add(1, router, route, method, strconv.Itoa(statusCode))
+	m.requestDuration.observe(duration.Seconds(), router, route, method)
+}
+
+func (m *Metrics) ObserveUpstream(source string, operation string, duration time.Duration, err error) {
+
+	m.upstreamRequests.add(1, source, operation)
+	m.upstreamDuration.observe(duration.Seconds(), source, operation)
+	if err != nil {
+		m.upstreamErrors.add(1, source, operation)
+	}
+}
+
+func (m *Metrics) ObserveCache(kind string, hit bool) {
+
+	result := resultMiss
+	if hit {
+		result = resultHit
+	}
+	m.cacheRequests.add(1, kind, result)
+}
+
+func (m *Metrics) AddBytesStreamed(alias string, n int64) {
+
+	if n > 0 {
+		m.bytesStreamed.add(float64(n), alias)
+	}
+}
+
+func (m *Metrics) SetCatalogVersion(version string) {
+
+	m.catalogVersion.replace(1, version)
+}
+
+// WriteTo пишет все метрики в текстовом формате Prometheus.
+func (m *Metrics) WriteTo(w io.Writer) (n int64, err error) {
+
+	var buf bytes.Buffer
+	for _, write := range []func(io.Writer) error{
+		m.requests.write,
+		m.requestDuration.write,
+		m.upstreamRequests.write,
+		m.upstreamErrors.write,
+		m.upstreamDuration.write,
+		m.cacheRequests.write,
+		m.bytesStreamed.write,
+		m.catalogVersion.write,
+	} {
+		if err = write(&buf); err != nil {
+			return
+		}
+	}
+
+	return buf.WriteTo(w)
+}
@@ -6,24 +6,36 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/seniorGolang/tg-proxy/core"
+	"github.com/seniorGolang/tg-proxy/errs"
 	"github.com/seniorGolang/tg-proxy/helpers"
 )
 
+const headerWarning = "Warning"
+
+// staleWarningWriter выставляет заголовок Warning перед отправкой статуса, если ответ собран из устаревших данных кеша.
+type staleWarningWriter struct {
+	http.ResponseWriter
+	report      *core.StaleReport
+	wroteHeader bool
+}
+
 func (p *Proxy) publicAuthMiddleware(next http.HandlerFunc) (handler http.HandlerFunc) {
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		if p.publicAuth != nil {
 			var err error
 			if err = p.publicAuth.Authorize(r); err != nil {
-				slog.Info("Authorization failed",
+				slog.InfoContext(r.Context(), "Authorization failed",
 					slog.String(helpers.LogKeyAuthProvider, "public"),
 					slog.String(helpers.LogKeyMethod, r.Method),
 					slog.String(helpers.LogKeyPath, r.URL.Path),
 					slog.Any(helpers.LogKeyError, err),
 				)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				writeErrorNetHTTP(w, r, http.StatusUnauthorized, errs.ErrUnauthorized)
 				return
 			}
+			recordPrincipal(r.Context(), p.publicAuth, r.Header)
 		}
 		next(w, r)
 	}
@@ -35,15 +47,16 @@ func (p *Proxy) adminAuthMiddleware(next http.HandlerFunc) (handler http.Handler
 		if p.adminAuth != nil {
 			var err error
 			if err = p.adminAuth.Authorize(r); err != nil {
-				slog.Info("Authorization failed",
+				slog.InfoContext(r.Context(), "Authorization failed",
 					slog.String(helpers.LogKeyAuthProvider, "admin"),
 					slog.String(helpers.LogKeyMethod, r.Method),
 					slog.String(helpers.LogKeyPath, r.URL.Path),
 					slog.Any(helpers.LogKeyError, err),
 				)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				writeErrorNetHTTP(w, r, http.StatusUnauthorized, errs.ErrUnauthorized)
 				return
 			}
+			recordPrincipal(r.Context(), p.adminAuth, r.Header)
 		}
 		next(w, r)
 	}
@@ -56,22 +69,18 @@ func (p *Proxy) publicFiberAuthMiddleware(c *fiber.Ctx) (err error) {
 		var ok bool
 		if fiberAuth, ok = p.publicAuth.(FiberAuthProvider); ok {
 			if err = fiberAuth.AuthorizeFiber(c); err != nil {
-				slog.Info("Authorization failed",
+				slog.InfoContext(c.UserContext(), "Authorization failed",
 					slog.String(helpers.LogKeyAuthProvider, "public"),
 					slog.String(helpers.LogKeyMethod, c.Method()),
 					slog.String(helpers.LogKeyPath, c.Path()),
 					slog.Any(helpers.LogKeyError, err),
 				)
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Unauthorized",
-				})
+				return writeErrorFiber(c, fiber.StatusUnauthorized, errs.ErrUnauthorized)
 			}
 		} else {
 			var httpReq *http.Request
 			if httpReq, err = http.NewRequest(c.Method(), string(c.Request().URI().FullURI()), nil); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to process request",
-				})
+				return writeErrorFiber(c, fiber.StatusInternalServerError, err)
 			}
 			for key, values := range c.GetReqHeaders() {
 				if len(values) > 0 {
@@ -82,17 +91,16 @@ func (p *Proxy) publicFiberAuthMiddleware(c *fiber.Ctx) (err error) {
 				}
 			}
 			if err = p.publicAuth.Authorize(httpReq); err != nil {
-				slog.Info("Authorization failed",
+				slog.InfoContext(c.UserContext(), "Authorization failed",
 					slog.String(helpers.LogKeyAuthProvider, "public"),
 					slog.String(helpers.LogKeyMethod, c.Method()),
 					slog.String(helpers.LogKeyPath, c.Path()),
 					slog.Any(helpers.LogKeyError, err),
 				)
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Unauthorized",
-				})
+				return writeErrorFiber(c, fiber.StatusUnauthorized, errs.ErrUnauthorized)
 			}
 		}
+		recordPrincipal(c.UserContext(), p.publicAuth, http.Header(c.GetReqHeaders()))
 	}
 	return c.Next()
 }
@@ -104,22 +112,18 @@ func (p *Proxy) adminFiberAuthMiddleware(c *fiber.Ctx) (err error) {
 		var ok bool
 		if fiberAuth, ok = p.adminAuth.(FiberAuthProvider); ok {
 			if err = fiberAuth.AuthorizeFiber(c); err != nil {
-				slog.Info("Authorization failed",
+				slog.InfoContext(c.UserContext(), "Authorization failed",
 					slog.String(helpers.LogKeyAuthProvider, "admin"),
 					slog.String(helpers.LogKeyMethod, c.Method()),
 					slog.String(helpers.LogKeyPath, c.Path()),
 					slog.Any(helpers.LogKeyError, err),
 				)
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Unauthorized",
-				})
+				return writeErrorFiber(c, fiber.StatusUnauthorized, errs.ErrUnauthorized)
 			}
 		} else {
 			var httpReq *http.Request
 			if httpReq, err = http.NewRequest(c.Method(), string(c.Request().URI().FullURI()), nil); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to process request",
-				})
+				return writeErrorFiber(c, fiber.StatusInternalServerError, err)
 			}
 			for key, values := range c.GetReqHeaders() {
 				if len(values) > 0 {
@@ -130,17 +134,62 @@ func (p *Proxy) adminFiberAuthMiddleware(c *fiber.Ctx) (err error) {
 				}
 			}
 			if err = p.adminAuth.Authorize(httpReq); err != nil {
-				slog.Info("Authorization failed",
+				slog.InfoContext(c.UserContext(), "Authorization failed",
 					slog.String(helpers.LogKeyAuthProvider, "admin"),
 					slog.String(helpers.LogKeyMethod, c.Method()),
 					slog.String(helpers.LogKeyPath, c.Path()),
 					slog.Any(helpers.LogKeyError, err),
 				)
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Unauthorized",
-				})
+				return writeErrorFiber(c, fiber.StatusUnauthorized, errs.ErrUnauthorized)
 			}
 		}
+		recordPrincipal(c.UserContext(), p.adminAuth, http.Header(c.GetReqHeaders()))
 	}
 	return c.Next()
 }
+
+// staleWarningMiddleware добавляет в контекст запроса отчёт об устаревших данных: если движок отдал данные из кеша
+// после истечения TTL (например, пока источник недоступен), ответ получает заголовок Warning.
+func (p *Proxy) staleWarningMiddleware(next http.HandlerFunc) (handler http.HandlerFunc) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, report := core.WithStaleReport(r.Context())
+		next(&staleWarningWriter{ResponseWriter: w, report: report}, r.WithContext(ctx))
+	}
+}
+
+func (p *Proxy) staleWarningFiberMiddleware(c *fiber.Ctx) (err error) {
+
+	ctx, report := core.WithStaleReport(c.UserContext())
+	c.SetUserContext(ctx)
+
+	err = c.Next()
+	if warning := report.Warning(); warning != "" {
+		c.Set(headerWarning, warning)
+	}
+	return
+}
+
+func (w *staleWarningWriter) WriteHeader(statusCode int) {
+
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if warning := w.report.Warning(); warning != "" {
+			w.Header().Set(headerWarning, warning)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *staleWarningWriter) Write(p []byte) (n int, err error) {
+
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *staleWarningWriter) Unwrap() (rw http.ResponseWriter) {
+
+	return w.ResponseWriter
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"time"
+)
+
+// FileInfo — метаданные файла, сохранённого в локальном кеше.
+type FileInfo struct {
+	Digest      string
+	Size        int64
+	ContentType string
+	// ETag и ModTime — валидаторы из ответа источника; по ним клиент делает условные запросы.
+	ETag    string
+	ModTime time.Time
+}
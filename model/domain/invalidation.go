@@ -0,0 +1,20 @@
+package domain
+
+type InvalidationKind string
+
+const (
+	// InvalidateProject сбрасывает закешированный проект, его версии, манифесты и отметки отсутствия.
+	InvalidateProject InvalidationKind = "project"
+	// InvalidateFiles удаляет файлы проекта из локального кеша файлов.
+	InvalidateFiles InvalidationKind = "files"
+	// InvalidateAggregate сбрасывает агрегированный манифест.
+	InvalidateAggregate InvalidationKind = "aggregate"
+)
+
+// Invalidation — событие сброса кеша, рассылаемое между репликами. Origin — идентификатор отправителя:
+// реплика не применяет повторно собственные события.
+type Invalidation struct {
+	Origin string
+	Kind   InvalidationKind
+	Alias  string
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// Project — проект прокси. Settings — настройки проекта для его источника (например, имя generic-пакета GitLab);
+// ключи определяет источник.
 type Project struct {
 	ID             uuid.UUID
 	Alias          string
@@ -14,6 +16,7 @@ type Project struct {
 	Token          string
 	Description    string
 	SourceName     string
+	Settings       map[string]string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -0,0 +1,8 @@
+package domain
+
+// UpstreamResponse — тело ответа источника с его валидаторами; по ним источник перепроверяет ответ условным запросом.
+type UpstreamResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+}
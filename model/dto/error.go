@@ -0,0 +1,13 @@
+package dto
+
+// ErrorResponse — тело ответа с ошибкой в формате RFC 7807 (application/problem+json)
+// с расширениями code и request_id.
+type ErrorResponse struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
@@ -9,28 +9,32 @@ import (
 )
 
 type ProjectCreateRequest struct {
-	Alias       string `json:"alias" validate:"required,min=1,max=255"`
-	RepoURL     string `json:"repo_url" validate:"required,url"`
-	Token       string `json:"token,omitempty" validate:"omitempty"`
-	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
-	SourceName  string `json:"source_name" validate:"required"`
+	Alias       string            `json:"alias" validate:"required,min=1,max=255"`
+	RepoURL     string            `json:"repo_url" validate:"required,url"`
+	Token       string            `json:"token,omitempty" validate:"omitempty"`
+	Description string            `json:"description,omitempty" validate:"omitempty,max=1000"`
+	SourceName  string            `json:"source_name" validate:"required"`
+	Settings    map[string]string `json:"settings,omitempty"`
 }
 
+// ProjectUpdateRequest — nil-поля не меняются; Settings заменяет настройки целиком, пустой объект их очищает.
 type ProjectUpdateRequest struct {
-	RepoURL     *string `json:"repo_url,omitempty" validate:"omitempty,url"`
-	Token       *string `json:"token,omitempty" validate:"omitempty"`
-	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
-	SourceName  *string `json:"source_name,omitempty" validate:"omitempty,required"`
+	RepoURL     *string           `json:"repo_url,omitempty" validate:"omitempty,url"`
+	Token       *string           `json:"token,omitempty" validate:"omitempty"`
+	Description *string           `json:"description,omitempty" validate:"omitempty,max=1000"`
+	SourceName  *string           `json:"source_name,omitempty" validate:"omitempty,required"`
+	Settings    map[string]string `json:"settings,omitempty"`
 }
 
 type ProjectResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Alias       string    `json:"alias"`
-	RepoURL     string    `json:"repo_url"`
-	Description string    `json:"description,omitempty"`
-	SourceName  string    `json:"source_name,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID         `json:"id"`
+	Alias       string            `json:"alias"`
+	RepoURL     string            `json:"repo_url"`
+	Description string            `json:"description,omitempty"`
+	SourceName  string            `json:"source_name,omitempty"`
+	Settings    map[string]string `json:"settings,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
 func (dto *ProjectCreateRequest) ToDomain() (project domain.Project) {
@@ -40,6 +44,7 @@ func (dto *ProjectCreateRequest) ToDomain() (project domain.Project) {
 		Token:       dto.Token,
 		Description: dto.Description,
 		SourceName:  dto.SourceName,
+		Settings:    dto.Settings,
 	}
 }
 
@@ -59,6 +64,7 @@ func (dto *ProjectUpdateRequest) ToDomain(alias string) (project domain.Project)
 	if dto.SourceName != nil {
 		project.SourceName = *dto.SourceName
 	}
+	project.Settings = dto.Settings
 
 	return project
 }
@@ -70,6 +76,7 @@ func FromDomain(project domain.Project) (resp ProjectResponse) {
 		RepoURL:     project.RepoURL,
 		Description: project.Description,
 		SourceName:  project.SourceName,
+		Settings:    project.Settings,
 		CreatedAt:   project.CreatedAt,
 		UpdatedAt:   project.UpdatedAt,
 	}
@@ -3,15 +3,20 @@ package tgproxy
 import (
 	"strings"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/seniorGolang/tg-proxy/helpers"
 )
 
 type Proxy struct {
-	engine       engine
-	baseURL      string
-	publicPrefix string
-	publicAuth   AuthProvider
-	adminAuth    AuthProvider
+	engine             engine
+	baseURL            string
+	publicPrefix       string
+	publicAuth         AuthProvider
+	adminAuth          AuthProvider
+	metrics            metricsRecorder
+	tracer             trace.Tracer
+	healthCheckSources bool
 }
 
 type ProxyOption func(*Proxy)
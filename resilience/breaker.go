@@ -0,0 +1,103 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// BreakerStatus — состояние circuit breaker одного хоста для логов и проверки готовности.
+type BreakerStatus struct {
+	Host      string
+	State     string
+	Failures  int
+	OpenUntil time.Time
+}
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	// outcomeIgnored — запрос отменил клиент; о доступности хоста это ничего не говорит
+	outcomeIgnored
+)
+
+// breaker размыкается после threshold сбоев подряд, через cooldown пропускает один пробный запрос
+// и по его результату замыкается или снова размыкается.
+type breaker struct {
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newBreaker() (b *breaker) {
+
+	return &breaker{state: StateClosed}
+}
+
+func (b *breaker) allow(now time.Time) (ok bool) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if now.Before(b.openUntil) {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (b *breaker) record(result outcome, threshold int, cooldown time.Duration, now time.Time) (from string, to string) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+	switch result {
+	case outcomeIgnored:
+		b.probing = false
+	case outcomeSuccess:
+		b.state = StateClosed
+		b.failures = 0
+		b.probing = false
+	case outcomeFailure:
+		b.failures++
+		if b.state == StateHalfOpen || b.failures >= threshold {
+			b.state = StateOpen
+			b.openUntil = now.Add(cooldown)
+			b.probing = false
+		}
+	}
+	return from, b.state
+}
+
+func (b *breaker) status(host string) (status BreakerStatus) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status = BreakerStatus{Host: host, State: b.state, Failures: b.failures}
+	if b.state != StateClosed {
+		status.OpenUntil = b.openUntil
+	}
+	return
+}
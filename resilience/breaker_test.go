@@ -0,0 +1,59 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerStateMachine(t *testing.T) {
+
+	const threshold = 2
+	const cooldown = time.Minute
+	now := time.Now()
+	b := newBreaker()
+
+	step := func(result outcome, wantState string) {
+		t.Helper()
+		if _, to := b.record(result, threshold, cooldown, now); to != wantState {
+			t.Fatalf("expected %s, got %s", wantState, to)
+		}
+	}
+
+	step(outcomeFailure, StateClosed)
+	step(outcomeSuccess, StateClosed)
+	// успех обнуляет счётчик: размыкают только сбои подряд
+	step(outcomeFailure, StateClosed)
+	step(outcomeFailure, StateOpen)
+	if b.allow(now.Add(cooldown - time.Second)) {
+		t.Fatal("expected open breaker to reject requests during cooldown")
+	}
+
+	now = now.Add(cooldown)
+	if !b.allow(now) {
+		t.Fatal("expected a probe after cooldown")
+	}
+	if b.allow(now) {
+		t.Fatal("expected a single probe in half-open state")
+	}
+	// пробный запрос отменил клиент — следующий запрос становится пробным
+	step(outcomeIgnored, StateHalfOpen)
+	if !b.allow(now) {
+		t.Fatal("expected a new probe after the canceled one")
+	}
+	step(outcomeFailure, StateOpen)
+	if status := b.status("example.com"); !status.OpenUntil.Equal(now.Add(cooldown)) {
+		t.Fatalf("expected failed probe to restart cooldown, got %v", status.OpenUntil)
+	}
+
+	now = now.Add(cooldown)
+	if !b.allow(now) {
+		t.Fatal("expected a probe after the second cooldown")
+	}
+	step(outcomeSuccess, StateClosed)
+	if status := b.status("example.com"); status.Failures != 0 || !status.OpenUntil.IsZero() {
+		t.Fatalf("expected closed breaker to reset, got %+v", status)
+	}
+	if !b.allow(now) || !b.allow(now) {
+		t.Fatal("expected closed breaker to allow all requests")
+	}
+}
@@ -0,0 +1,53 @@
+package resilience
+
+import (
+	"time"
+)
+
+type Option func(*Transport)
+
+// MaxRetries — сколько раз повторять идемпотентный запрос после сбоя (по умолчанию 2). 0 отключает повторы.
+func MaxRetries(n int) (opt Option) {
+	return func(t *Transport) {
+		t.maxRetries = n
+	}
+}
+
+// Backoff задаёт экспоненциальную задержку между повторами: base, 2·base, 4·base… но не больше max, со случайным разбросом.
+func Backoff(base time.Duration, max time.Duration) (opt Option) {
+	return func(t *Transport) {
+		t.baseDelay = base
+		t.maxDelay = max
+	}
+}
+
+// MaxRetryAfter ограничивает ожидание по заголовку Retry-After (по умолчанию 30 секунд):
+// если источник просит ждать дольше, его ответ возвращается без повтора.
+func MaxRetryAfter(d time.Duration) (opt Option) {
+	return func(t *Transport) {
+		t.maxRetryAfter = d
+	}
+}
+
+// BreakerThreshold — число сбоев подряд, после которого запросы к хосту перестают отправляться (по умолчанию 5).
+// 0 отключает circuit breaker.
+func BreakerThreshold(n int) (opt Option) {
+	return func(t *Transport) {
+		t.breakerThreshold = n
+	}
+}
+
+// BreakerCooldown — сколько breaker остаётся открытым, прежде чем пропустить пробный запрос (по умолчанию 30 секунд).
+func BreakerCooldown(d time.Duration) (opt Option) {
+	return func(t *Transport) {
+		t.breakerCooldown = d
+	}
+}
+
+// RetryRateLimited — повторять ли запросы, получившие 429 (по умолчанию да). Источник, который сам
+// переключает токены при исчерпании лимита, отключает повторы, чтобы не ждать с тем же токеном.
+func RetryRateLimited(retry bool) (opt Option) {
+	return func(t *Transport) {
+		t.retryRateLimited = retry
+	}
+}
@@ -0,0 +1,268 @@
+package resilience
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+)
+
+const (
+	defaultMaxRetries       = 2
+	defaultBaseDelay        = 200 * time.Millisecond
+	defaultMaxDelay         = 5 * time.Second
+	defaultMaxRetryAfter    = 30 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+
+	// drainLimit — сколько байт тела неудачного ответа дочитывается, чтобы соединение вернулось в пул.
+	drainLimit = 64 << 10
+)
+
+// Transport повторяет идемпотентные запросы к источнику при сетевых ошибках, 429 и 5xx и держит
+// circuit breaker на каждый хост: пока хост недоступен, запросы сразу завершаются ошибкой errs.ErrSourceUnavailable.
+type Transport struct {
+	base             http.RoundTripper
+	source           string
+	maxRetries       int
+	baseDelay        time.Duration
+	maxDelay         time.Duration
+	maxRetryAfter    time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	retryRateLimited bool
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// NewTransport оборачивает base (nil — http.DefaultTransport); source — имя источника для логов.
+func NewTransport(base http.RoundTripper, source string, opts ...Option) (t *Transport) {
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t = &Transport{
+		base:             base,
+		source:           source,
+		maxRetries:       defaultMaxRetries,
+		baseDelay:        defaultBaseDelay,
+		maxDelay:         defaultMaxDelay,
+		maxRetryAfter:    defaultMaxRetryAfter,
+		breakerThreshold: defaultBreakerThreshold,
+		breakerCooldown:  defaultBreakerCooldown,
+		retryRateLimited: true,
+		breakers:         make(map[string]*breaker),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return
+}
+
+// Breakers возвращает состояние breaker всех хостов, к которым уже были запросы, в порядке имён хостов.
+func (t *Transport) Breakers() (statuses []BreakerStatus) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for host, b := range t.breakers {
+		statuses = append(statuses, b.status(host))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Host < statuses[j].Host })
+	return
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+
+	b := t.breaker(req.URL.Host)
+	retryable := isIdempotent(req)
+
+	for attempt := 0; ; attempt++ {
+		if !t.allow(b) {
+			return nil, fmt.Errorf("%w: circuit open for %s", errs.ErrSourceUnavailable, req.URL.Host)
+		}
+
+		resp, err = t.base.RoundTrip(req)
+		t.record(req, b, t.outcome(req, resp, err))
+
+		if !retryable || attempt >= t.maxRetries || !t.shouldRetry(req, resp, err) {
+			return
+		}
+
+		delay, ok := t.retryDelay(req, attempt, resp)
+		if !ok {
+			return
+		}
+
+		args := []any{
+			slog.String(helpers.LogKeySource, t.source),
+			slog.String(helpers.LogKeyHost, req.URL.Host),
+			slog.Int(helpers.LogKeyAttempt, attempt+1),
+			slog.Duration(helpers.LogKeyRetryDelay, delay),
+		}
+		if err != nil {
+			args = append(args, slog.Any(helpers.LogKeyError, err))
+		} else {
+			args = append(args, slog.Int(helpers.LogKeyStatusCode, resp.StatusCode))
+			drain(resp)
+		}
+		slog.DebugContext(req.Context(), "Retrying upstream request", args...)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *Transport) breaker(host string) (b *breaker) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var found bool
+	if b, found = t.breakers[host]; !found {
+		b = newBreaker()
+		t.breakers[host] = b
+	}
+	return
+}
+
+func (t *Transport) allow(b *breaker) (ok bool) {
+
+	if t.breakerThreshold <= 0 {
+		return true
+	}
+	return b.allow(time.Now())
+}
+
+func (t *Transport) outcome(req *http.Request, resp *http.Response, err error) (result outcome) {
+
+	switch {
+	case req.Context().Err() != nil:
+		return outcomeIgnored
+	case err != nil:
+		return outcomeFailure
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return outcomeFailure
+	}
+	return outcomeSuccess
+}
+
+func (t *Transport) record(req *http.Request, b *breaker, result outcome) {
+
+	if t.breakerThreshold <= 0 {
+		return
+	}
+
+	from, to := b.record(result, t.breakerThreshold, t.breakerCooldown, time.Now())
+	if from == to {
+		return
+	}
+
+	switch to {
+	case StateOpen:
+		slog.WarnContext(req.Context(), "Circuit breaker opened",
+			slog.String(helpers.LogKeySource, t.source),
+			slog.String(helpers.LogKeyHost, req.URL.Host),
+			slog.String(helpers.LogKeyCircuitState, to),
+			slog.Duration(helpers.LogKeyDuration, t.breakerCooldown),
+		)
+	case StateClosed:
+		slog.InfoContext(req.Context(), "Circuit breaker closed",
+			slog.String(helpers.LogKeySource, t.source),
+			slog.String(helpers.LogKeyHost, req.URL.Host),
+			slog.String(helpers.LogKeyCircuitState, to),
+		)
+	}
+}
+
+// retryDelay — пауза перед повтором: Retry-After источника или экспоненциальная задержка со случайным разбросом.
+// ok = false, если ждать дольше, чем позволяют MaxRetryAfter или дедлайн запроса.
+func (t *Transport) retryDelay(req *http.Request, attempt int, resp *http.Response) (delay time.Duration, ok bool) {
+
+	now := time.Now()
+	var found bool
+	if resp != nil {
+		if delay, found = ParseRetryAfter(resp.Header.Get("Retry-After"), now); found && delay > t.maxRetryAfter {
+			return 0, false
+		}
+	}
+	if !found {
+		backoff := t.baseDelay << attempt
+		if backoff <= 0 || backoff > t.maxDelay {
+			backoff = t.maxDelay
+		}
+		// половина задержки фиксирована, половина случайна, чтобы реплики не повторяли запросы синхронно
+		delay = backoff/2 + rand.N(backoff/2+1)
+	}
+
+	if deadline, hasDeadline := req.Context().Deadline(); hasDeadline && now.Add(delay).After(deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+func isIdempotent(req *http.Request) (ok bool) {
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error) (retry bool) {
+
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return t.retryRateLimited
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// ParseRetryAfter разбирает Retry-After в обеих формах: число секунд или HTTP-дата; прошедшая дата — нулевая пауза.
+func ParseRetryAfter(value string, now time.Time) (delay time.Duration, found bool) {
+
+	if value == "" {
+		return
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay = at.Sub(now); delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return
+}
+
+func drain(resp *http.Response) {
+
+	_, _ = io.CopyN(io.Discard, resp.Body, drainLimit)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,71 @@
+package resilience
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		delay time.Duration
+		found bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"1.5", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{now.Add(time.Hour).Format(time.RFC850), time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		delay, found := ParseRetryAfter(tt.value, now)
+		if delay != tt.delay || found != tt.found {
+			t.Errorf("ParseRetryAfter(%q) = %s, %v, want %s, %v", tt.value, delay, found, tt.delay, tt.found)
+		}
+	}
+}
+
+func TestTransportOpensBreaker(t *testing.T) {
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	transport := NewTransport(nil, "test", MaxRetries(0), BreakerThreshold(2), BreakerCooldown(time.Hour))
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	if _, err := client.Get(srv.URL); !errors.Is(err, errs.ErrSourceUnavailable) {
+		t.Fatalf("expected ErrSourceUnavailable from open breaker, got %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("expected open breaker to stop requests, got %d", n)
+	}
+	if statuses := transport.Breakers(); len(statuses) != 1 || statuses[0].State != StateOpen {
+		t.Fatalf("expected one open breaker, got %+v", statuses)
+	}
+}
@@ -0,0 +1,150 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/resilience"
+)
+
+const (
+	sourceName           = "gitea"
+	tokenAuthPrefix      = "token "
+	releasesDownloadPath = "/releases/download/"
+	releasesPageLimit    = 50
+	defaultResponseTTL   = 24 * time.Hour
+)
+
+// Source — источник для Gitea и Forgejo (API /api/v1 совместим).
+type Source struct {
+	baseURL     string
+	token       string
+	http        *http.Client
+	transport   *resilience.Transport
+	resilience  []resilience.Option
+	responses   helpers.ResponseStore
+	responseTTL time.Duration
+}
+
+func (s *Source) Info() (name, url string) {
+	return sourceName, s.baseURL
+}
+
+// Ping проверяет, что инстанс Gitea доступен по baseURL.
+func (s *Source) Ping(ctx context.Context) (err error) {
+
+	return helpers.PingURL(ctx, s.http, s.baseURL)
+}
+
+// CircuitBreakers — состояние circuit breaker по хостам источника; показывается в проверке готовности.
+func (s *Source) CircuitBreakers() (statuses []resilience.BreakerStatus) {
+
+	return s.transport.Breakers()
+}
+
+func NewClient(baseURL string, opts ...ClientOption) (src *Source) {
+
+	s := &Source{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		http:        &http.Client{},
+		responseTTL: defaultResponseTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.transport = resilience.NewTransport(s.http.Transport, sourceName, s.resilience...)
+	s.http.Transport = s.transport
+
+	return s
+}
+
+// fetch выполняет запрос и читает тело; с ResponseCache запрос условный (см. helpers.Revalidate).
+func (s *Source) fetch(req *http.Request) (statusCode int, body []byte, err error) {
+
+	return helpers.Revalidate(req, s.responses, s.responseTTL, s.http.Do)
+}
+
+func (s *Source) ParseFileURL(fileURL string) (version string, filename string, ok bool) {
+
+	parsed, err := url.Parse(fileURL)
+	if err != nil || parsed.Host == "" {
+		return
+	}
+
+	baseParsed, err := url.Parse(s.baseURL)
+	if err != nil || baseParsed.Host == "" {
+		return
+	}
+
+	if parsed.Scheme != baseParsed.Scheme || parsed.Host != baseParsed.Host {
+		return
+	}
+
+	idx := strings.Index(parsed.Path, releasesDownloadPath)
+	if idx == -1 {
+		return
+	}
+
+	after := strings.Trim(parsed.Path[idx+len(releasesDownloadPath):], "/")
+	if after == "" {
+		return
+	}
+
+	parts := strings.Split(after, "/")
+	if len(parts) != 2 {
+		return
+	}
+
+	version = parts[0]
+	filename = parts[1]
+	if version == "" || filename == "" {
+		return
+	}
+
+	return version, filename, true
+}
+
+func (s *Source) setAuth(req *http.Request, project domain.Project) {
+
+	var token string
+	if project.Token != "" {
+		token = project.Token
+	} else {
+		token = s.token
+	}
+	if token != "" {
+		req.Header.Set("Authorization", tokenAuthPrefix+token)
+	}
+}
+
+// extractOwnerRepo учитывает, что инстанс может быть развёрнут не в корне домена (baseURL с путём).
+func (s *Source) extractOwnerRepo(repoURL string) (owner string, repo string) {
+
+	var err error
+	var parsedURL *url.URL
+	if parsedURL, err = url.Parse(repoURL); err != nil {
+		return "", ""
+	}
+
+	repoPath := strings.Trim(parsedURL.Path, "/")
+	if baseParsed, baseErr := url.Parse(s.baseURL); baseErr == nil {
+		basePath := strings.Trim(baseParsed.Path, "/")
+		if basePath != "" {
+			repoPath = strings.TrimPrefix(strings.TrimPrefix(repoPath, basePath), "/")
+		}
+	}
+
+	owner, rest, _ := strings.Cut(repoPath, "/")
+	if rest != "" {
+		repo, _, _ = strings.Cut(rest, "/")
+		repo = strings.TrimSuffix(repo, ".git")
+	}
+
+	return
+}
@@ -0,0 +1,129 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/source/gitea/internal"
+)
+
+func (s *Source) GetFileStream(ctx context.Context, project domain.Project, version string, filename string) (stream io.ReadCloser, err error) {
+
+	var resp *http.Response
+	if resp, err = s.GetFileResponse(ctx, project, version, filename); err != nil {
+		return
+	}
+
+	stream = resp.Body
+	return
+}
+
+func (s *Source) GetFileResponse(ctx context.Context, project domain.Project, version string, filename string) (resp *http.Response, err error) {
+
+	return s.GetFileResponseWithHeader(ctx, project, version, filename, nil)
+}
+
+// GetFileResponseWithHeader передаёт Range и условные заголовки в Gitea; 206 и 304 возвращаются как есть.
+func (s *Source) GetFileResponseWithHeader(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	return s.fileResponse(ctx, http.MethodGet, project, version, filename, header)
+}
+
+// HeadFileResponse возвращает заголовки вложения Gitea без тела.
+func (s *Source) HeadFileResponse(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	return s.fileResponse(ctx, http.MethodHead, project, version, filename, header)
+}
+
+func (s *Source) fileResponse(ctx context.Context, method string, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	owner, repo := s.extractOwnerRepo(project.RepoURL)
+	if owner == "" || repo == "" {
+		err = fmt.Errorf("%w: invalid repo URL", errs.ErrGiteaAPI)
+		return
+	}
+
+	directURL := helpers.BuildURL(s.baseURL, owner, repo, "releases", "download", version, filename)
+
+	slog.DebugContext(ctx, "Gitea download request",
+		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+		slog.String(helpers.LogKeySource, sourceName),
+		slog.String(helpers.LogKeyRequestURL, directURL),
+		slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+		slog.String(helpers.LogKeyVersion, version),
+		slog.String(helpers.LogKeyFilename, filename),
+	)
+
+	if resp, err = s.download(ctx, method, project, directURL, header); err != nil {
+		return
+	}
+
+	if helpers.IsFileResponseStatus(resp.StatusCode) {
+		return
+	}
+
+	_ = resp.Body.Close()
+	return s.getFileFromRelease(ctx, method, project, owner, repo, version, filename, header)
+}
+
+// getFileFromRelease — запасной путь: ищем вложение релиза через API (например, если прямой URL закрыт для токена).
+func (s *Source) getFileFromRelease(ctx context.Context, method string, project domain.Project, owner string, repo string, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	var release internal.Release
+	if release, err = s.getRelease(ctx, project, owner, repo, version); err != nil {
+		return
+	}
+
+	var assetURL string
+	for _, asset := range release.Assets {
+		if asset.Name == filename {
+			assetURL = asset.BrowserDownloadURL
+			break
+		}
+	}
+
+	if assetURL == "" {
+		err = fmt.Errorf("%w: file %s not found in release %s", errs.ErrFileNotFound, filename, version)
+		return
+	}
+
+	if resp, err = s.download(ctx, method, project, assetURL, header); err != nil {
+		return
+	}
+
+	if !helpers.IsFileResponseStatus(resp.StatusCode) {
+		slog.DebugContext(ctx, "Gitea API error response",
+			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
+			slog.String(helpers.LogKeySource, sourceName),
+			slog.String(helpers.LogKeyRequestURL, assetURL),
+			slog.Int(helpers.LogKeyStatusCode, resp.StatusCode),
+			slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+			slog.String(helpers.LogKeyVersion, version),
+			slog.String(helpers.LogKeyFilename, filename),
+		)
+		_ = resp.Body.Close()
+		err = fmt.Errorf("%w: status %d", errs.ErrGiteaAPI, resp.StatusCode)
+		return
+	}
+
+	return
+}
+
+func (s *Source) download(ctx context.Context, method string, project domain.Project, fileURL string, header http.Header) (resp *http.Response, err error) {
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, fileURL, nil); err != nil {
+		return
+	}
+	s.setAuth(req, project)
+	req.Header.Set("Accept", "application/octet-stream")
+	helpers.ApplyRequestHeaders(req, header)
+
+	return s.http.Do(req)
+}
@@ -0,0 +1,101 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+var manifestNames = []string{"manifest.yaml", "manifest.yml"}
+
+func (s *Source) GetManifest(ctx context.Context, project domain.Project, version string) (manifest domain.Manifest, err error) {
+
+	owner, repo := s.extractOwnerRepo(project.RepoURL)
+	if owner == "" || repo == "" {
+		err = fmt.Errorf("%w: invalid repo URL", errs.ErrGiteaAPI)
+		return
+	}
+
+	if manifest, err = s.getManifestFromRelease(ctx, project, owner, repo, version); err == nil {
+		return manifest, nil
+	}
+
+	apiURL := helpers.BuildURLWithQuery(s.baseURL, map[string]string{"ref": version}, "api", "v1", "repos", owner, repo, "raw", "manifest.yml")
+
+	slog.DebugContext(ctx, "Gitea API request",
+		slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
+		slog.String(helpers.LogKeySource, sourceName),
+		slog.String(helpers.LogKeyRequestURL, apiURL),
+		slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+		slog.String(helpers.LogKeyVersion, version),
+	)
+
+	var resp *http.Response
+	if resp, err = s.download(ctx, http.MethodGet, project, apiURL, nil); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		slog.DebugContext(ctx, "Gitea API error response",
+			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
+			slog.String(helpers.LogKeySource, sourceName),
+			slog.String(helpers.LogKeyRequestURL, apiURL),
+			slog.Int(helpers.LogKeyStatusCode, resp.StatusCode),
+			slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+			slog.String(helpers.LogKeyVersion, version),
+		)
+		err = fmt.Errorf("%w: status %d", errs.ErrGiteaAPI, resp.StatusCode)
+		return
+	}
+
+	return s.decodeManifest(resp.Body)
+}
+
+func (s *Source) getManifestFromRelease(ctx context.Context, project domain.Project, owner string, repo string, version string) (manifest domain.Manifest, err error) {
+
+	for _, name := range manifestNames {
+		directURL := helpers.BuildURL(s.baseURL, owner, repo, "releases", "download", version, name)
+
+		var resp *http.Response
+		if resp, err = s.download(ctx, http.MethodGet, project, directURL, nil); err != nil {
+			return
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			continue
+		}
+		manifest, err = s.decodeManifest(resp.Body)
+		_ = resp.Body.Close()
+		return
+	}
+
+	err = fmt.Errorf("%w: manifest not found in release %s", errs.ErrManifestParseError, version)
+	return
+}
+
+func (s *Source) decodeManifest(body io.Reader) (manifest domain.Manifest, err error) {
+
+	var data []byte
+	if data, err = io.ReadAll(body); err != nil {
+		return
+	}
+
+	var modelManifest model.Manifest
+	if err = yaml.Unmarshal(data, &modelManifest); err != nil {
+		err = fmt.Errorf("%w: %w", errs.ErrManifestParseError, err)
+		return
+	}
+
+	manifest = modelManifest.ToDomain()
+	return
+}
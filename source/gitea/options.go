@@ -0,0 +1,45 @@
+package gitea
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/resilience"
+	"github.com/seniorGolang/tg-proxy/tracing"
+)
+
+type ClientOption func(*Source)
+
+func DefaultToken(token string) (opt ClientOption) {
+	return func(s *Source) {
+		s.token = token
+	}
+}
+
+// TracerProvider включает клиентские спаны для HTTP-запросов к API и передачу контекста трассы в заголовке traceparent.
+func TracerProvider(tp trace.TracerProvider) (opt ClientOption) {
+	return func(s *Source) {
+		s.http.Transport = tracing.NewTransport(s.http.Transport, tp)
+	}
+}
+
+// Resilience настраивает повторы и circuit breaker запросов к источнику (см. пакет resilience).
+// Без опции действуют значения по умолчанию: два повтора GET и breaker после пяти сбоев подряд.
+func Resilience(opts ...resilience.Option) (opt ClientOption) {
+	return func(s *Source) {
+		s.resilience = append(s.resilience, opts...)
+	}
+}
+
+// ResponseCache сохраняет списки релизов в store вместе с ETag и Last-Modified; повторные запросы идут
+// с If-None-Match, и на 304 тело берётся из store. ttl — срок хранения ответа (0 — значение по умолчанию, сутки).
+func ResponseCache(store helpers.ResponseStore, ttl time.Duration) (opt ClientOption) {
+	return func(s *Source) {
+		s.responses = store
+		if ttl > 0 {
+			s.responseTTL = ttl
+		}
+	}
+}
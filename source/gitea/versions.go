@@ -0,0 +1,122 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/source/gitea/internal"
+)
+
+func (s *Source) GetVersions(ctx context.Context, project domain.Project) (versions []string, err error) {
+
+	owner, repo := s.extractOwnerRepo(project.RepoURL)
+	if owner == "" || repo == "" {
+		err = fmt.Errorf("%w: invalid repo URL", errs.ErrGiteaAPI)
+		return
+	}
+
+	versions = make([]string, 0)
+	for page := 1; ; page++ {
+		var releases []internal.Release
+		if releases, err = s.listReleases(ctx, project, owner, repo, page); err != nil {
+			return
+		}
+		for _, release := range releases {
+			if release.Draft || release.TagName == "" {
+				continue
+			}
+			versions = append(versions, release.TagName)
+		}
+		if len(releases) < releasesPageLimit {
+			break
+		}
+	}
+
+	return
+}
+
+func (s *Source) listReleases(ctx context.Context, project domain.Project, owner string, repo string, page int) (releases []internal.Release, err error) {
+
+	apiURL := helpers.BuildURLWithQuery(
+		s.baseURL,
+		map[string]string{
+			"page":  strconv.Itoa(page),
+			"limit": strconv.Itoa(releasesPageLimit),
+		},
+		"api", "v1", "repos", owner, repo, "releases",
+	)
+
+	slog.DebugContext(ctx, "Gitea API request",
+		slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
+		slog.String(helpers.LogKeySource, sourceName),
+		slog.String(helpers.LogKeyRequestURL, apiURL),
+		slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+	)
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil); err != nil {
+		return
+	}
+	s.setAuth(req, project)
+	req.Header.Set("Accept", "application/json")
+
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req); err != nil {
+		return
+	}
+
+	if statusCode != http.StatusOK {
+		slog.DebugContext(ctx, "Gitea API error response",
+			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
+			slog.String(helpers.LogKeySource, sourceName),
+			slog.String(helpers.LogKeyRequestURL, apiURL),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
+			slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+		)
+		err = fmt.Errorf("%w: status %d", errs.ErrGiteaAPI, statusCode)
+		return
+	}
+
+	if err = json.Unmarshal(body, &releases); err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Source) getRelease(ctx context.Context, project domain.Project, owner string, repo string, tag string) (release internal.Release, err error) {
+
+	apiURL := helpers.BuildURL(s.baseURL, "api", "v1", "repos", owner, repo, "releases", "tags", tag)
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil); err != nil {
+		return
+	}
+	s.setAuth(req, project)
+	req.Header.Set("Accept", "application/json")
+
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req); err != nil {
+		return
+	}
+
+	if statusCode != http.StatusOK {
+		err = fmt.Errorf("%w: status %d", errs.ErrGiteaAPI, statusCode)
+		return
+	}
+
+	if err = json.Unmarshal(body, &release); err != nil {
+		return
+	}
+
+	return
+}
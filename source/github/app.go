@@ -0,0 +1,266 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/source/github/internal"
+)
+
+const (
+	// appJWTTTL — GitHub принимает JWT приложения не дольше 10 минут; iat сдвигается назад на случай расхождения часов.
+	appJWTTTL      = 9 * time.Minute
+	appJWTBackdate = time.Minute
+	// tokenRefreshBefore — за сколько до истечения токен установки перевыпускается.
+	tokenRefreshBefore = 5 * time.Minute
+	// noInstallationTTL — как долго помнить, что репозиторий не входит ни в одну установку приложения.
+	noInstallationTTL = 5 * time.Minute
+)
+
+// App — учётные данные GitHub App: идентификатор приложения и закрытый ключ для подписи JWT.
+type App struct {
+	id  int64
+	key *rsa.PrivateKey
+}
+
+func NewApp(appID int64, privateKeyPEM string) (app *App, err error) {
+
+	var key *rsa.PrivateKey
+	if key, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM)); err != nil {
+		return
+	}
+
+	app = &App{id: appID, key: key}
+	return
+}
+
+type installation struct {
+	id      int64
+	expires time.Time
+}
+
+type installationToken struct {
+	token   string
+	expires time.Time
+}
+
+// appAuth выпускает токены установок приложения и кеширует их вместе с установками репозиториев.
+// Установка ищется для каждого репозитория: приложение может быть установлено только на выбранные репозитории владельца.
+type appAuth struct {
+	app           *App
+	http          *http.Client
+	apiBaseURL    string
+	mu            sync.Mutex
+	installations map[string]installation
+	tokens        map[int64]installationToken
+	flight        singleflight.Group
+}
+
+func newAppAuth(app *App, client *http.Client, apiBaseURL string) (auth *appAuth) {
+
+	return &appAuth{
+		app:           app,
+		http:          client,
+		apiBaseURL:    apiBaseURL,
+		installations: make(map[string]installation),
+		tokens:        make(map[int64]installationToken),
+	}
+}
+
+// token возвращает токен установки приложения, в которую входит репозиторий; пустой токен — приложение для него не установлено.
+// Конкурентные запросы одного репозитория ждут общий выпуск токена; он не отменяется, если первый из них ушёл.
+func (a *appAuth) token(ctx context.Context, owner string, repo string) (token string, err error) {
+
+	key := owner + "/" + repo
+	if token, found := a.cached(key, time.Now()); found {
+		return token, nil
+	}
+
+	flightCtx := context.WithoutCancel(ctx)
+	ch := a.flight.DoChan(key, func() (value any, err error) {
+		if token, found := a.cached(key, time.Now()); found {
+			return token, nil
+		}
+		return a.mint(flightCtx, owner, repo)
+	})
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	case res := <-ch:
+		if res.Err != nil {
+			err = res.Err
+			return
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// cached — токен из кеша, если он не истекает в ближайшие tokenRefreshBefore, или отметка, что установки нет.
+func (a *appAuth) cached(key string, now time.Time) (token string, found bool) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	inst, exists := a.installations[key]
+	if !exists {
+		return
+	}
+	if inst.id == 0 {
+		return "", now.Before(inst.expires)
+	}
+
+	cached, exists := a.tokens[inst.id]
+	if !exists || !now.Before(cached.expires.Add(-tokenRefreshBefore)) {
+		return
+	}
+	return cached.token, true
+}
+
+func (a *appAuth) mint(ctx context.Context, owner string, repo string) (token string, err error) {
+
+	var id int64
+	if id, err = a.installation(ctx, owner, repo); err != nil || id == 0 {
+		return
+	}
+
+	var appJWT string
+	if appJWT, err = a.signJWT(time.Now()); err != nil {
+		return
+	}
+
+	apiURL := helpers.BuildURL(a.apiBaseURL, "app", "installations", strconv.FormatInt(id, 10), "access_tokens")
+
+	var resp *http.Response
+	if resp, err = a.request(ctx, http.MethodPost, apiURL, appJWT); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		if resp.StatusCode == http.StatusNotFound {
+			// установку удалили: при следующем запросе репозитории ищут её заново
+			a.mu.Lock()
+			for key, inst := range a.installations {
+				if inst.id == id {
+					delete(a.installations, key)
+				}
+			}
+			a.mu.Unlock()
+		}
+		err = fmt.Errorf("%w: installation token for %s/%s: status %d", errs.ErrGitHubAPI, owner, repo, resp.StatusCode)
+		return
+	}
+
+	var minted internal.InstallationToken
+	if err = json.NewDecoder(resp.Body).Decode(&minted); err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	a.tokens[id] = installationToken{token: minted.Token, expires: minted.ExpiresAt}
+	a.mu.Unlock()
+
+	slog.DebugContext(ctx, "GitHub App installation token issued",
+		slog.String(helpers.LogKeySource, sourceName),
+		slog.Int64(helpers.LogKeyInstallation, id),
+		slog.String(helpers.LogKeyTokenMasked, helpers.MaskToken(minted.Token)),
+	)
+
+	return minted.Token, nil
+}
+
+// installation ищет установку приложения, в которую входит репозиторий, и запоминает её для этого репозитория.
+func (a *appAuth) installation(ctx context.Context, owner string, repo string) (id int64, err error) {
+
+	key := owner + "/" + repo
+	a.mu.Lock()
+	inst, exists := a.installations[key]
+	a.mu.Unlock()
+	if exists && inst.id != 0 {
+		return inst.id, nil
+	}
+
+	var appJWT string
+	if appJWT, err = a.signJWT(time.Now()); err != nil {
+		return
+	}
+
+	apiURL := helpers.BuildURL(a.apiBaseURL, "repos", owner, repo, "installation")
+
+	var resp *http.Response
+	if resp, err = a.request(ctx, http.MethodGet, apiURL, appJWT); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		slog.DebugContext(ctx, "GitHub App is not installed for repository",
+			slog.String(helpers.LogKeySource, sourceName),
+			slog.String(helpers.LogKeyOwner, owner),
+			slog.String(helpers.LogKeyRepo, repo),
+		)
+		a.mu.Lock()
+		a.installations[key] = installation{expires: time.Now().Add(noInstallationTTL)}
+		a.mu.Unlock()
+		return
+	default:
+		err = fmt.Errorf("%w: installation lookup for %s/%s: status %d", errs.ErrGitHubAPI, owner, repo, resp.StatusCode)
+		return
+	}
+
+	var found internal.Installation
+	if err = json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	a.installations[key] = installation{id: found.ID}
+	a.mu.Unlock()
+
+	return found.ID, nil
+}
+
+func (a *appAuth) request(ctx context.Context, method string, apiURL string, appJWT string) (resp *http.Response, err error) {
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, apiURL, nil); err != nil {
+		return
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+
+	return a.http.Do(req)
+}
+
+func (a *appAuth) signJWT(now time.Time) (signed string, err error) {
+
+	claims := jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(a.app.id, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-appJWTBackdate)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(appJWTTTL)),
+	}
+
+	if signed, err = jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(a.app.key); err != nil {
+		err = fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+		return
+	}
+
+	return
+}
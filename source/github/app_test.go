@@ -0,0 +1,282 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+const testAppID = 42
+
+// fakeGitHubAPI — API GitHub для проверки авторизации приложения: установки репозиториев, выпуск токенов
+// и ресурс, который запоминает заголовок Authorization последнего запроса.
+type fakeGitHubAPI struct {
+	t   *testing.T
+	key *rsa.PrivateKey
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	installations map[string]int64
+	tokenTTL      time.Duration
+	mintDelay     time.Duration
+	failMints     bool
+	lookups       int
+	mints         int
+	lastAuth      string
+}
+
+func newFakeGitHubAPI(t *testing.T) (api *fakeGitHubAPI) {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api = &fakeGitHubAPI{
+		t:             t,
+		key:           key,
+		installations: make(map[string]int64),
+		tokenTTL:      time.Hour,
+	}
+	api.srv = httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+	t.Cleanup(api.srv.Close)
+	return
+}
+
+func (api *fakeGitHubAPI) privateKeyPEM() (keyPEM string) {
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(api.key)}))
+}
+
+func (api *fakeGitHubAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "repos" && parts[3] == "installation" && r.Method == http.MethodGet:
+		api.lookups++
+		if !api.checkJWT(w, r) {
+			return
+		}
+		id, found := api.installations[parts[1]+"/"+parts[2]]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id})
+	case len(parts) == 4 && parts[0] == "app" && parts[3] == "access_tokens" && r.Method == http.MethodPost:
+		api.mints++
+		if !api.checkJWT(w, r) {
+			return
+		}
+		if api.failMints {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// выпуск идёт без блокировки, чтобы конкурентные запросы успели встать в ожидание
+		api.mu.Unlock()
+		time.Sleep(api.mintDelay)
+		api.mu.Lock()
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%s_%d", parts[2], api.mints),
+			"expires_at": time.Now().Add(api.tokenTTL),
+		})
+	default:
+		api.lastAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("ok"))
+	}
+}
+
+func (api *fakeGitHubAPI) checkJWT(w http.ResponseWriter, r *http.Request) (ok bool) {
+
+	signed := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token, err := jwt.Parse(signed, func(token *jwt.Token) (key any, err error) {
+		return &api.key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithIssuer(fmt.Sprint(testAppID)), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		api.t.Errorf("invalid app JWT: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (api *fakeGitHubAPI) counters() (lookups int, mints int) {
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return api.lookups, api.mints
+}
+
+func (api *fakeGitHubAPI) authorization(t *testing.T, src *Source, project domain.Project) (auth string) {
+
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, api.srv.URL+"/resource", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := src.do(req, project, "Bearer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return api.lastAuth
+}
+
+func newAppSource(t *testing.T, api *fakeGitHubAPI, opts ...ClientOption) (src *Source) {
+
+	app, err := NewApp(testAppID, api.privateKeyPEM())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(append([]ClientOption{BaseURL(api.srv.URL), APIBaseURL(api.srv.URL), AppAuth(app)}, opts...)...)
+}
+
+func TestAppAuthMintsAndReusesInstallationToken(t *testing.T) {
+
+	api := newFakeGitHubAPI(t)
+	api.installations["acme/tool"] = 7
+	src := newAppSource(t, api)
+	project := domain.Project{RepoURL: api.srv.URL + "/acme/tool"}
+
+	first := api.authorization(t, src, project)
+	second := api.authorization(t, src, project)
+
+	if first != "Bearer ghs_7_1" || second != first {
+		t.Fatalf("expected installation token to be reused, got %q and %q", first, second)
+	}
+	if lookups, mints := api.counters(); lookups != 1 || mints != 1 {
+		t.Fatalf("expected 1 lookup and 1 mint, got %d and %d", lookups, mints)
+	}
+}
+
+func TestAppAuthRefreshesTokenBeforeExpiry(t *testing.T) {
+
+	api := newFakeGitHubAPI(t)
+	api.installations["acme/tool"] = 7
+	api.tokenTTL = tokenRefreshBefore - time.Minute
+	src := newAppSource(t, api)
+	project := domain.Project{RepoURL: api.srv.URL + "/acme/tool"}
+
+	first := api.authorization(t, src, project)
+	second := api.authorization(t, src, project)
+
+	if first == second {
+		t.Fatalf("expected token expiring within %s to be reissued, got %q twice", tokenRefreshBefore, first)
+	}
+	if lookups, mints := api.counters(); lookups != 1 || mints != 2 {
+		t.Fatalf("expected installation to be cached and token minted twice, got %d lookups and %d mints", lookups, mints)
+	}
+}
+
+func TestAppAuthProjectTokenHasPriority(t *testing.T) {
+
+	api := newFakeGitHubAPI(t)
+	api.installations["acme/tool"] = 7
+	src := newAppSource(t, api, DefaultToken("default-pat"))
+
+	auth := api.authorization(t, src, domain.Project{RepoURL: api.srv.URL + "/acme/tool", Token: "project-pat"})
+
+	if auth != "Bearer project-pat" {
+		t.Fatalf("expected project token, got %q", auth)
+	}
+	if lookups, mints := api.counters(); lookups != 0 || mints != 0 {
+		t.Fatalf("expected no app requests, got %d lookups and %d mints", lookups, mints)
+	}
+}
+
+func TestAppAuthInstallationPerRepository(t *testing.T) {
+
+	api := newFakeGitHubAPI(t)
+	api.installations["acme/tool"] = 7
+	src := newAppSource(t, api, DefaultToken("default-pat"))
+
+	// приложение установлено только на acme/tool: другие репозитории владельца идут с токеном из пула
+	if auth := api.authorization(t, src, domain.Project{RepoURL: api.srv.URL + "/acme/other"}); auth != "Bearer default-pat" {
+		t.Fatalf("expected default token for repository outside installation, got %q", auth)
+	}
+	if auth := api.authorization(t, src, domain.Project{RepoURL: api.srv.URL + "/acme/tool"}); auth != "Bearer ghs_7_1" {
+		t.Fatalf("expected installation token for selected repository, got %q", auth)
+	}
+	if auth := api.authorization(t, src, domain.Project{RepoURL: api.srv.URL + "/acme/other"}); auth != "Bearer default-pat" {
+		t.Fatalf("expected missing installation to stay cached, got %q", auth)
+	}
+	if lookups, _ := api.counters(); lookups != 2 {
+		t.Fatalf("expected one lookup per repository, got %d", lookups)
+	}
+}
+
+func TestAppAuthFallsBackToTokenPool(t *testing.T) {
+
+	api := newFakeGitHubAPI(t)
+	api.installations["acme/tool"] = 7
+	api.failMints = true
+	src := newAppSource(t, api, DefaultToken("default-pat"))
+
+	if auth := api.authorization(t, src, domain.Project{RepoURL: api.srv.URL + "/acme/tool"}); auth != "Bearer default-pat" {
+		t.Fatalf("expected default token when installation token cannot be minted, got %q", auth)
+	}
+	if _, mints := api.counters(); mints != 1 {
+		t.Fatalf("expected mint attempt, got %d", mints)
+	}
+}
+
+func TestAppAuthWaitersSurviveCanceledCaller(t *testing.T) {
+
+	api := newFakeGitHubAPI(t)
+	api.installations["acme/tool"] = 7
+	api.mintDelay = 100 * time.Millisecond
+	src := newAppSource(t, api)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := src.appTokens.token(canceled, "acme", "tool")
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan string, 1)
+	go func() {
+		token, err := src.appTokens.token(context.Background(), "acme", "tool")
+		if err != nil {
+			t.Errorf("waiter failed: %v", err)
+		}
+		second <- token
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-first; err != context.Canceled {
+		t.Fatalf("expected canceled caller to get context.Canceled, got %v", err)
+	}
+	if token := <-second; token != "ghs_7_1" {
+		t.Fatalf("expected waiter to get minted token, got %q", token)
+	}
+	if _, mints := api.counters(); mints != 1 {
+		t.Fatalf("expected single mint, got %d", mints)
+	}
+}
@@ -1,46 +1,179 @@
 package github
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/resilience"
 )
 
 const (
 	versionPrefix        = "v"
 	sourceName           = "github"
-	baseURL              = "https://github.com"
-	apiBaseURL           = "https://api.github.com"
-	releasesURL          = "https://github.com/%s/%s/releases/download/%s"
-	gitInfoRefs          = "https://github.com/%s/%s.git/info/refs"
+	defaultBaseURL       = "https://github.com"
+	defaultAPIBaseURL    = "https://api.github.com"
+	enterpriseAPIPath    = "/api/v3"
 	gitService           = "?service=git-upload-pack"
 	releasesDownloadPath = "/releases/download/"
+	defaultResponseTTL   = 24 * time.Hour
 )
 
 type Source struct {
-	baseURL string
-	token   string
-	http    *http.Client
+	name        string
+	baseURL     string
+	apiBaseURL  string
+	token       string
+	fallback    []string
+	tokens      *tokenPool
+	http        *http.Client
+	transport   *resilience.Transport
+	resilience  []resilience.Option
+	responses   helpers.ResponseStore
+	responseTTL time.Duration
+	app         *App
+	appTokens   *appAuth
 }
 
 func (s *Source) Info() (name, url string) {
-	return sourceName, baseURL
+	return s.name, s.baseURL
+}
+
+// Ping обращается к корню API: для github.com это api.github.com, для Enterprise — /api/v3.
+func (s *Source) Ping(ctx context.Context) (err error) {
+
+	return helpers.PingURL(ctx, s.http, s.apiBaseURL)
+}
+
+// CircuitBreakers — состояние circuit breaker по хостам источника; показывается в проверке готовности.
+func (s *Source) CircuitBreakers() (statuses []resilience.BreakerStatus) {
+
+	return s.transport.Breakers()
 }
 
+// RateLimits — остаток лимита API по токенам пула (токены замаскированы) по последним ответам GitHub.
+func (s *Source) RateLimits() (limits []RateLimit) {
+
+	return s.tokens.snapshot()
+}
+
+// NewClient по умолчанию работает с github.com. Для GitHub Enterprise Server задаётся BaseURL
+// (и при нестандартном расположении API — APIBaseURL), а для нескольких инстансов — разные Name.
 func NewClient(opts ...ClientOption) (src *Source) {
 
 	src = &Source{
-		baseURL: baseURL,
-		http:    &http.Client{},
+		name:        sourceName,
+		baseURL:     defaultBaseURL,
+		http:        &http.Client{},
+		responseTTL: defaultResponseTTL,
 	}
 
 	for _, opt := range opts {
 		opt(src)
 	}
+	src.tokens = newTokenPool(append([]string{src.token}, src.fallback...))
+	transportOpts := src.resilience
+	if src.tokens.size() > 1 {
+		// на 429 do переключается на следующий токен пула — повтор с тем же токеном только задержал бы его
+		transportOpts = append([]resilience.Option{resilience.RetryRateLimited(false)}, src.resilience...)
+	}
+	src.transport = resilience.NewTransport(src.http.Transport, src.name, transportOpts...)
+	src.http.Transport = src.transport
+
+	src.baseURL = strings.TrimSuffix(src.baseURL, "/")
+	if src.apiBaseURL == "" {
+		if src.baseURL == defaultBaseURL {
+			src.apiBaseURL = defaultAPIBaseURL
+		} else {
+			src.apiBaseURL = src.baseURL + enterpriseAPIPath
+		}
+	}
+	src.apiBaseURL = strings.TrimSuffix(src.apiBaseURL, "/")
+	if src.app != nil {
+		src.appTokens = newAppAuth(src.app, src.http, src.apiBaseURL)
+	}
 
 	return
 }
 
+// do выполняет запрос с токеном проекта, затем — с токеном установки GitHub App, в которую входит репозиторий, а если нет
+// ни того, ни другого или токен установки выпустить не удалось — с токеном из пула. Когда GitHub отказывает по лимиту, запрос повторяется со следующим токеном пула;
+// если исчерпаны все, возвращается *errs.RateLimitError.
+func (s *Source) do(req *http.Request, project domain.Project, scheme string) (resp *http.Response, err error) {
+
+	fixed := project.Token
+	if fixed == "" && s.appTokens != nil {
+		owner, repo := s.extractOwnerRepo(project.RepoURL)
+		if fixed, err = s.appTokens.token(req.Context(), owner, repo); err != nil {
+			if req.Context().Err() != nil {
+				return
+			}
+			// сбой GitHub App не должен ронять запрос: он уходит с токеном из пула, как без приложения
+			slog.WarnContext(req.Context(), "GitHub App token unavailable, falling back to token pool",
+				slog.String(helpers.LogKeySource, sourceName),
+				slog.String(helpers.LogKeyOwner, owner),
+				slog.String(helpers.LogKeyRepo, repo),
+				slog.Any(helpers.LogKeyError, err),
+			)
+			fixed, err = "", nil
+		}
+	}
+
+	var reset time.Time
+	for attempt := 0; attempt < s.tokens.size(); attempt++ {
+		token := fixed
+		if token == "" {
+			var ok bool
+			if token, reset, ok = s.tokens.pick(time.Now()); !ok {
+				break
+			}
+		}
+
+		next := req.Clone(req.Context())
+		if token != "" {
+			next.Header.Set("Authorization", scheme+" "+token)
+		}
+		if resp, err = s.http.Do(next); err != nil {
+			return
+		}
+
+		var limited bool
+		if reset, limited = s.tokens.observe(req.Context(), token, resp, time.Now()); !limited {
+			return
+		}
+		_ = resp.Body.Close()
+		if fixed != "" {
+			break
+		}
+	}
+
+	return nil, &errs.RateLimitError{Source: s.name, Reset: reset}
+}
+
+// fetch выполняет запрос через do и читает тело; с ResponseCache запрос условный (см. helpers.Revalidate).
+func (s *Source) fetch(req *http.Request, project domain.Project) (statusCode int, body []byte, err error) {
+
+	return helpers.Revalidate(req, s.responses, s.responseTTL, func(req *http.Request) (resp *http.Response, err error) {
+		return s.do(req, project, "Bearer")
+	})
+}
+
+func (s *Source) releaseDownloadURL(owner string, repo string, tag string, filename string) (downloadURL string) {
+
+	return helpers.BuildURL(s.baseURL, owner, repo, "releases", "download", tag, filename)
+}
+
+func (s *Source) gitInfoRefsURL(owner string, repo string) (refsURL string) {
+
+	return helpers.BuildURL(s.baseURL, owner, repo+".git", "info", "refs") + gitService
+}
+
 func (s *Source) ParseFileURL(fileURL string) (version string, filename string, ok bool) {
 
 	parsed, err := url.Parse(fileURL)
@@ -35,6 +35,23 @@ func (s *Source) GetFileStream(ctx context.Context, project domain.Project, vers
 
 func (s *Source) GetFileResponse(ctx context.Context, project domain.Project, version string, filename string) (resp *http.Response, err error) {
 
+	return s.GetFileResponseWithHeader(ctx, project, version, filename, nil)
+}
+
+// GetFileResponseWithHeader передаёт Range и условные заголовки в GitHub; 206 и 304 возвращаются как есть.
+func (s *Source) GetFileResponseWithHeader(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	return s.fileResponse(ctx, http.MethodGet, project, version, filename, header)
+}
+
+// HeadFileResponse запрашивает у GitHub только заголовки файла.
+func (s *Source) HeadFileResponse(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	return s.fileResponse(ctx, http.MethodHead, project, version, filename, header)
+}
+
+func (s *Source) fileResponse(ctx context.Context, method string, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
 	owner, repo := s.extractOwnerRepo(project.RepoURL)
 	if owner == "" || repo == "" {
 		err = fmt.Errorf("%w: invalid repo URL", errs.ErrGitHubAPI)
@@ -42,65 +59,48 @@ func (s *Source) GetFileResponse(ctx context.Context, project domain.Project, ve
 	}
 
 	tag := ensureVersionPrefix(version)
-	directURL := fmt.Sprintf(releasesURL+"/%s", owner, repo, tag, filename)
+	directURL := s.releaseDownloadURL(owner, repo, tag, filename)
 
 	var req *http.Request
-	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, directURL, nil); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, method, directURL, nil); err != nil {
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
 	req.Header.Set("Accept", "application/octet-stream")
+	helpers.ApplyRequestHeaders(req, header)
 
-	if resp, err = s.http.Do(req); err != nil {
+	if resp, err = s.do(req, project, "Bearer"); err != nil {
 		return
 	}
 
-	if resp.StatusCode == http.StatusOK {
+	if helpers.IsFileResponseStatus(resp.StatusCode) {
 		return
 	}
 
 	_ = resp.Body.Close()
-	return s.getFileFromRelease(ctx, project, version, filename)
+	return s.getFileFromRelease(ctx, method, project, version, filename, header)
 }
 
-func (s *Source) getFileFromRelease(ctx context.Context, project domain.Project, version string, filename string) (resp *http.Response, err error) {
+func (s *Source) getFileFromRelease(ctx context.Context, method string, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
 
 	owner, repo := s.extractOwnerRepo(project.RepoURL)
-	apiURL := helpers.BuildURL(apiBaseURL, "repos", owner, repo, "releases", "tags", version)
+	apiURL := helpers.BuildURL(s.apiBaseURL, "repos", owner, repo, "releases", "tags", version)
 
 	var req *http.Request
 	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil); err != nil {
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	var releaseResp *http.Response
-	if releaseResp, err = s.http.Do(req); err != nil {
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req, project); err != nil {
 		return
 	}
-	defer releaseResp.Body.Close()
 
-	if releaseResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("%w: status %d", errs.ErrGitHubAPI, releaseResp.StatusCode)
+	if statusCode != http.StatusOK {
+		err = fmt.Errorf("%w: status %d", errs.ErrGitHubAPI, statusCode)
 		return
 	}
 
@@ -111,7 +111,7 @@ func (s *Source) getFileFromRelease(ctx context.Context, project domain.Project,
 			URL  string `json:"url"`
 		} `json:"assets"`
 	}
-	if err = json.NewDecoder(releaseResp.Body).Decode(&release); err != nil {
+	if err = json.Unmarshal(body, &release); err != nil {
 		return
 	}
 
@@ -128,20 +128,18 @@ func (s *Source) getFileFromRelease(ctx context.Context, project domain.Project,
 		return
 	}
 
-	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, method, assetURL, nil); err != nil {
 		return
 	}
 
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
-	}
 	req.Header.Set("Accept", "application/octet-stream")
+	helpers.ApplyRequestHeaders(req, header)
 
-	if resp, err = s.http.Do(req); err != nil {
+	if resp, err = s.do(req, project, "token"); err != nil {
 		return
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if !helpers.IsFileResponseStatus(resp.StatusCode) {
 		_ = resp.Body.Close()
 		err = fmt.Errorf("%w: status %d", errs.ErrGitHubAPI, resp.StatusCode)
 		return
@@ -0,0 +1,14 @@
+package internal
+
+import (
+	"time"
+)
+
+type Installation struct {
+	ID int64 `json:"id"`
+}
+
+type InstallationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -32,25 +31,16 @@ func (s *Source) GetManifest(ctx context.Context, project domain.Project, versio
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	var resp *http.Response
-	if resp, err = s.http.Do(req); err != nil {
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req, project); err != nil {
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("%w: status %d", errs.ErrGitHubAPI, resp.StatusCode)
+	if statusCode != http.StatusOK {
+		err = fmt.Errorf("%w: status %d", errs.ErrGitHubAPI, statusCode)
 		return
 	}
 
@@ -58,7 +48,7 @@ func (s *Source) GetManifest(ctx context.Context, project domain.Project, versio
 		Content  string `json:"content"`
 		Encoding string `json:"encoding"`
 	}
-	if err = s.decodeJSONResponse(resp.Body, &contentsResponse); err != nil {
+	if err = json.Unmarshal(body, &contentsResponse); err != nil {
 		return
 	}
 
@@ -92,36 +82,21 @@ func (s *Source) getManifestFromRelease(ctx context.Context, project domain.Proj
 
 	tag := ensureVersionPrefix(version)
 	for _, name := range []string{"manifest.yaml", "manifest.yml"} {
-		directURL := fmt.Sprintf(releasesURL+"/%s", owner, repo, tag, name)
+		directURL := s.releaseDownloadURL(owner, repo, tag, name)
 		var req *http.Request
 		if req, err = http.NewRequestWithContext(ctx, http.MethodGet, directURL, nil); err != nil {
 			return
 		}
-		var token string
-		if project.Token != "" {
-			token = project.Token
-		} else {
-			token = s.token
-		}
-		if token != "" {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-		}
 		req.Header.Set("Accept", "application/octet-stream")
 
-		var resp *http.Response
-		if resp, err = s.http.Do(req); err != nil {
+		var statusCode int
+		var data []byte
+		if statusCode, data, err = s.fetch(req, project); err != nil {
 			return
 		}
-		if resp.StatusCode != http.StatusOK {
-			_ = resp.Body.Close()
+		if statusCode != http.StatusOK {
 			continue
 		}
-		var data []byte
-		if data, err = io.ReadAll(resp.Body); err != nil {
-			_ = resp.Body.Close()
-			return
-		}
-		_ = resp.Body.Close()
 
 		var modelManifest model.Manifest
 		if err = yaml.Unmarshal(data, &modelManifest); err != nil {
@@ -139,7 +114,7 @@ func (s *Source) getManifestFromRelease(ctx context.Context, project domain.Proj
 func (s *Source) buildContentsURL(repoURL string, ref string, path string) (apiURL string) {
 
 	owner, repo := s.extractOwnerRepo(repoURL)
-	apiURL = helpers.BuildURLWithQuery(apiBaseURL, map[string]string{"ref": ref}, "repos", owner, repo, "contents", path)
+	apiURL = helpers.BuildURLWithQuery(s.apiBaseURL, map[string]string{"ref": ref}, "repos", owner, repo, "contents", path)
 	return
 }
 
@@ -160,17 +135,3 @@ func (s *Source) extractOwnerRepo(repoURL string) (owner string, repo string) {
 
 	return
 }
-
-func (s *Source) decodeJSONResponse(body io.Reader, target interface{}) (err error) {
-
-	var data []byte
-	if data, err = io.ReadAll(body); err != nil {
-		return
-	}
-
-	if err = json.Unmarshal(data, target); err != nil {
-		return
-	}
-
-	return
-}
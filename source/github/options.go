@@ -1,5 +1,15 @@
 package github
 
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/resilience"
+	"github.com/seniorGolang/tg-proxy/tracing"
+)
+
 type ClientOption func(*Source)
 
 func DefaultToken(token string) (opt ClientOption) {
@@ -7,3 +17,68 @@ func DefaultToken(token string) (opt ClientOption) {
 		s.token = token
 	}
 }
+
+// AppAuth включает аутентификацию как GitHub App: для проектов без собственного токена прокси находит установку
+// приложения у владельца репозитория и выпускает её токен доступа; токен кешируется до скорого истечения.
+// Если приложение у владельца не установлено, используются DefaultToken и FallbackTokens.
+func AppAuth(app *App) (opt ClientOption) {
+	return func(s *Source) {
+		s.app = app
+	}
+}
+
+// FallbackTokens — запасные токены для проектов без собственного токена: когда у DefaultToken исчерпан
+// лимит API, запросы идут со следующим токеном, у которого лимит ещё есть.
+func FallbackTokens(tokens ...string) (opt ClientOption) {
+	return func(s *Source) {
+		s.fallback = append(s.fallback, tokens...)
+	}
+}
+
+// BaseURL — веб-адрес инстанса (например, https://github.example.com для GitHub Enterprise Server).
+// Если APIBaseURL не задан, API берётся как BaseURL + /api/v3.
+func BaseURL(baseURL string) (opt ClientOption) {
+	return func(s *Source) {
+		s.baseURL = baseURL
+	}
+}
+
+func APIBaseURL(apiBaseURL string) (opt ClientOption) {
+	return func(s *Source) {
+		s.apiBaseURL = apiBaseURL
+	}
+}
+
+// Name — имя источника при регистрации в движке; нужно, чтобы зарегистрировать несколько GitHub-инстансов.
+func Name(name string) (opt ClientOption) {
+	return func(s *Source) {
+		s.name = name
+	}
+}
+
+// TracerProvider включает клиентские спаны для HTTP-запросов к API и передачу контекста трассы в заголовке traceparent.
+func TracerProvider(tp trace.TracerProvider) (opt ClientOption) {
+	return func(s *Source) {
+		s.http.Transport = tracing.NewTransport(s.http.Transport, tp)
+	}
+}
+
+// Resilience настраивает повторы и circuit breaker запросов к источнику (см. пакет resilience).
+// Без опции действуют значения по умолчанию: два повтора GET и breaker после пяти сбоев подряд.
+func Resilience(opts ...resilience.Option) (opt ClientOption) {
+	return func(s *Source) {
+		s.resilience = append(s.resilience, opts...)
+	}
+}
+
+// ResponseCache сохраняет ответы со списком тегов, релизами и манифестами в store вместе с ETag и Last-Modified.
+// Повторные запросы идут с If-None-Match: ответ 304 не расходует лимит API, а тело берётся из store.
+// ttl — срок хранения ответа (0 — значение по умолчанию, сутки). Подходят cache/memory, cache/redis и cache/tiered.
+func ResponseCache(store helpers.ResponseStore, ttl time.Duration) (opt ClientOption) {
+	return func(s *Source) {
+		s.responses = store
+		if ttl > 0 {
+			s.responseTTL = ttl
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/resilience"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+
+	// defaultRateLimitWait — пауза для вторичного лимита GitHub, если он не прислал ни Retry-After, ни X-RateLimit-Reset.
+	defaultRateLimitWait = time.Minute
+)
+
+// RateLimit — остаток бюджета запросов к API одного токена пула по последнему ответу GitHub.
+type RateLimit struct {
+	Token     string
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+type tokenBudget struct {
+	token     string
+	limit     int
+	remaining int
+	reset     time.Time
+	known     bool
+}
+
+// tokenPool хранит основной и запасные токены источника. Запросы идут с первым токеном, у которого не исчерпан лимит,
+// поэтому запасные используются только после исчерпания основного.
+type tokenPool struct {
+	mu      sync.Mutex
+	budgets []*tokenBudget
+}
+
+func newTokenPool(tokens []string) (pool *tokenPool) {
+
+	pool = &tokenPool{}
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+		pool.budgets = append(pool.budgets, &tokenBudget{token: token})
+	}
+	if len(pool.budgets) == 0 {
+		// анонимные запросы тоже ограничены, их лимит учитывается так же
+		pool.budgets = append(pool.budgets, &tokenBudget{})
+	}
+	return
+}
+
+// pick возвращает токен с неисчерпанным лимитом; если исчерпаны все, ok = false и reset — ближайший сброс.
+func (p *tokenPool) pick(now time.Time) (token string, reset time.Time, ok bool) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, budget := range p.budgets {
+		if !budget.exhausted(now) {
+			return budget.token, time.Time{}, true
+		}
+		if reset.IsZero() || budget.reset.Before(reset) {
+			reset = budget.reset
+		}
+	}
+	return "", reset, false
+}
+
+// observe обновляет бюджет токена по заголовкам ответа и помечает его исчерпанным, если GitHub отказал по лимиту.
+func (p *tokenPool) observe(ctx context.Context, token string, resp *http.Response, now time.Time) (reset time.Time, limited bool) {
+
+	reset, limited = rateLimited(resp, now)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	budget := p.budget(token)
+	if budget == nil {
+		return
+	}
+	if limit, found := parseRateLimit(resp.Header); found {
+		budget.limit = limit.Limit
+		budget.remaining = limit.Remaining
+		budget.reset = limit.Reset
+		budget.known = true
+	}
+	if limited {
+		budget.remaining = 0
+		budget.reset = reset
+		budget.known = true
+		slog.WarnContext(ctx, "GitHub rate limit exhausted",
+			slog.String(helpers.LogKeySource, sourceName),
+			slog.String(helpers.LogKeyTokenMasked, helpers.MaskToken(token)),
+			slog.Time(helpers.LogKeyRateLimitReset, reset),
+		)
+	}
+	return
+}
+
+func (p *tokenPool) snapshot() (limits []RateLimit) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, budget := range p.budgets {
+		if !budget.known {
+			continue
+		}
+		limits = append(limits, RateLimit{
+			Token:     helpers.MaskToken(budget.token),
+			Limit:     budget.limit,
+			Remaining: budget.remaining,
+			Reset:     budget.reset,
+		})
+	}
+	return
+}
+
+func (p *tokenPool) size() (n int) {
+
+	return len(p.budgets)
+}
+
+func (p *tokenPool) budget(token string) (budget *tokenBudget) {
+
+	for _, b := range p.budgets {
+		if b.token == token {
+			return b
+		}
+	}
+	return nil
+}
+
+func (b *tokenBudget) exhausted(now time.Time) (ok bool) {
+
+	return b.known && b.remaining == 0 && now.Before(b.reset)
+}
+
+// parseRateLimit читает X-RateLimit-*; found = false, если GitHub их не прислал (например, для скачивания ассетов).
+func parseRateLimit(header http.Header) (limit RateLimit, found bool) {
+
+	remaining := header.Get(headerRateLimitRemaining)
+	if remaining == "" {
+		return
+	}
+
+	var err error
+	if limit.Remaining, err = strconv.Atoi(remaining); err != nil {
+		return
+	}
+	limit.Limit, _ = strconv.Atoi(header.Get(headerRateLimitLimit))
+	if reset, parseErr := strconv.ParseInt(header.Get(headerRateLimitReset), 10, 64); parseErr == nil {
+		limit.Reset = time.Unix(reset, 0)
+	}
+	return limit, true
+}
+
+// rateLimited — ответ означает отказ по лимиту: 429 или 403 с нулевым остатком либо с Retry-After (вторичный лимит).
+func rateLimited(resp *http.Response, now time.Time) (reset time.Time, limited bool) {
+
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	retryAfter := resp.Header.Get(headerRetryAfter)
+	limit, found := parseRateLimit(resp.Header)
+	switch {
+	case retryAfter != "":
+		delay, _ := resilience.ParseRetryAfter(retryAfter, now)
+		return now.Add(delay), true
+	case found && limit.Remaining == 0:
+		if limit.Reset.IsZero() {
+			limit.Reset = now.Add(defaultRateLimitWait)
+		}
+		return limit.Reset, true
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return now.Add(defaultRateLimitWait), true
+	}
+	return
+}
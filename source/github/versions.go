@@ -3,7 +3,6 @@ package github
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 
@@ -44,40 +43,24 @@ func (s *Source) GetVersions(ctx context.Context, project domain.Project) (versi
 
 func (s *Source) listTags(ctx context.Context, owner string, repo string, project domain.Project) (tags []string, err error) {
 
-	gitURL := fmt.Sprintf(gitInfoRefs, owner, repo) + gitService
+	gitURL := s.gitInfoRefsURL(owner, repo)
 
 	var req *http.Request
 	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, gitURL, nil); err != nil {
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
 	req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
 	req.Header.Set("Accept", "*/*")
 
-	var resp *http.Response
-	if resp, err = s.http.Do(req); err != nil {
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var body []byte
-		body, _ = io.ReadAll(resp.Body)
-		err = fmt.Errorf("%w: status %d, body: %s", errs.ErrGitHubAPI, resp.StatusCode, string(body))
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req, project); err != nil {
 		return
 	}
 
-	var body []byte
-	if body, err = io.ReadAll(resp.Body); err != nil {
+	if statusCode != http.StatusOK {
+		err = fmt.Errorf("%w: status %d, body: %s", errs.ErrGitHubAPI, statusCode, string(body))
 		return
 	}
 
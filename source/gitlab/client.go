@@ -1,41 +1,188 @@
 package gitlab
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/resilience"
+)
+
+const (
+	sourceName           = "gitlab"
+	privateTokenHeader   = "PRIVATE-TOKEN" // GitLab API требует именно "PRIVATE-TOKEN" (все заглавные)
+	genericPackagesPath  = "/packages/generic/"
+	releasesPath         = "/-/releases/"
+	releaseDownloadsPath = "/downloads/"
+	defaultPackageName   = "release"
+	defaultResponseTTL   = 24 * time.Hour
+	maxRedirects         = 10
 )
 
+// Ключи Project.Settings, которые понимает источник.
 const (
-	sourceName         = "gitlab"
-	privateTokenHeader = "PRIVATE-TOKEN" // GitLab API требует именно "PRIVATE-TOKEN" (все заглавные)
-	genericReleasePath = "/packages/generic/release/"
+	// SettingPackageName — имя generic-пакета с версиями проекта (по умолчанию release или PackageName источника).
+	SettingPackageName = "package_name"
+	// SettingMode — откуда брать версии и файлы: ModePackages или ModeReleases.
+	SettingMode = "mode"
+)
+
+const (
+	// ModePackages — версии пакета из реестра generic-пакетов, файлы — из пакета той же версии.
+	ModePackages = "packages"
+	// ModeReleases — версии из релизов GitLab, файлы — по ссылкам на ассеты релиза.
+	ModeReleases = "releases"
 )
 
 type Source struct {
-	baseURL string
-	token   string
-	http    *http.Client
+	baseURL     string
+	token       string
+	packageName string
+	mode        string
+	http        *http.Client
+	transport   *resilience.Transport
+	resilience  []resilience.Option
+	responses   helpers.ResponseStore
+	responseTTL time.Duration
 }
 
 func (s *Source) Info() (name, url string) {
 	return sourceName, s.baseURL
 }
 
+// Ping — проверка готовности: отвечает ли инстанс GitLab.
+func (s *Source) Ping(ctx context.Context) (err error) {
+
+	return helpers.PingURL(ctx, s.http, s.baseURL)
+}
+
+// CircuitBreakers — состояние circuit breaker по хостам источника; показывается в проверке готовности.
+func (s *Source) CircuitBreakers() (statuses []resilience.BreakerStatus) {
+
+	return s.transport.Breakers()
+}
+
 func NewClient(baseURL string, opts ...ClientOption) (src *Source) {
 
 	s := &Source{
-		baseURL: baseURL,
-		http:    &http.Client{},
+		baseURL:     baseURL,
+		packageName: defaultPackageName,
+		mode:        ModePackages,
+		http:        &http.Client{CheckRedirect: dropTokenOnRedirect},
+		responseTTL: defaultResponseTTL,
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
+	// режим по умолчанию — конфигурация сервиса: ошибка в нём должна остановить запуск, а не каждый запрос
+	if !knownMode(s.mode) {
+		panic(fmt.Sprintf("gitlab: unknown mode %q, expected %q or %q", s.mode, ModePackages, ModeReleases))
+	}
+	s.transport = resilience.NewTransport(s.http.Transport, sourceName, s.resilience...)
+	s.http.Transport = s.transport
 
 	return s
 }
 
+// dropTokenOnRedirect не передаёт PRIVATE-TOKEN на другой хост: ссылки на ассеты релизов и файлы пакетов
+// перенаправляют во внешние хранилища, а net/http снимает при редиректе только стандартные заголовки авторизации.
+func dropTokenOnRedirect(req *http.Request, via []*http.Request) (err error) {
+
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		req.Header.Del(privateTokenHeader)
+	}
+	return
+}
+
+// setAuth добавляет PRIVATE-TOKEN только к запросам на сам GitLab: ссылка на ассет релиза без direct_asset_url
+// может вести на сторонний хост, и туда запрос уходит без учётных данных.
+func (s *Source) setAuth(req *http.Request, project domain.Project) {
+
+	if !s.isOwnURL(req.URL) {
+		return
+	}
+
+	token := s.token
+	if project.Token != "" {
+		token = project.Token
+	}
+	if token != "" {
+		req.Header.Set(privateTokenHeader, token) //nolint:canonicalheader
+	}
+}
+
+// isOwnURL — адрес на том же хосте и по той же схеме, что и baseURL источника.
+func (s *Source) isOwnURL(target *url.URL) (ok bool) {
+
+	base, err := url.Parse(s.baseURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(target.Scheme, base.Scheme) && strings.EqualFold(target.Host, base.Host)
+}
+
+// projectMode — режим проекта из Settings, по умолчанию — режим источника. Неизвестный режим проекта
+// (сохранённый до проверки настроек) — errs.ErrInvalidSourceSettings.
+func (s *Source) projectMode(project domain.Project) (mode string, err error) {
+
+	if mode = project.Settings[SettingMode]; mode == "" {
+		mode = s.mode
+	}
+	if !knownMode(mode) {
+		err = fmt.Errorf("%w: unknown %s %q, expected %q or %q", errs.ErrInvalidSourceSettings, SettingMode, mode, ModePackages, ModeReleases)
+	}
+	return
+}
+
+func knownMode(mode string) (ok bool) {
+
+	return mode == ModePackages || mode == ModeReleases
+}
+
+// ValidateSettings допускает только SettingPackageName и SettingMode с одним из известных режимов.
+func (s *Source) ValidateSettings(settings map[string]string) (err error) {
+
+	for key, value := range settings {
+		switch key {
+		case SettingPackageName:
+		case SettingMode:
+			if value != "" && !knownMode(value) {
+				return fmt.Errorf("%w: unknown %s %q, expected %q or %q", errs.ErrInvalidSourceSettings, SettingMode, value, ModePackages, ModeReleases)
+			}
+		default:
+			return fmt.Errorf("%w: unknown setting %q", errs.ErrInvalidSourceSettings, key)
+		}
+	}
+	return
+}
+
+func (s *Source) projectPackageName(project domain.Project) (name string) {
+
+	if name = project.Settings[SettingPackageName]; name == "" {
+		name = s.packageName
+	}
+	return
+}
+
+// fetch выполняет запрос и читает тело; с ResponseCache запрос условный (см. helpers.Revalidate).
+func (s *Source) fetch(req *http.Request) (statusCode int, body []byte, err error) {
+
+	return helpers.Revalidate(req, s.responses, s.responseTTL, s.http.Do)
+}
+
+// ParseFileURL распознаёт ссылки на файлы generic-пакета (/packages/generic/<пакет>/<версия>/<файл>)
+// и на ассеты релиза (/-/releases/<тег>/downloads/<путь>).
 func (s *Source) ParseFileURL(fileURL string) (version string, filename string, ok bool) {
 
 	parsed, err := url.Parse(fileURL)
@@ -52,25 +199,24 @@ func (s *Source) ParseFileURL(fileURL string) (version string, filename string,
 		return
 	}
 
-	idx := strings.Index(parsed.Path, genericReleasePath)
-	if idx == -1 {
-		return
-	}
-
-	after := strings.Trim(parsed.Path[idx+len(genericReleasePath):], "/")
-	if after == "" {
-		return
+	if idx := strings.Index(parsed.Path, genericPackagesPath); idx != -1 {
+		parts := strings.Split(strings.Trim(parsed.Path[idx+len(genericPackagesPath):], "/"), "/")
+		if len(parts) != 3 || parts[0] == "" {
+			return
+		}
+		version, filename = parts[1], parts[2]
+	} else if idx = strings.Index(parsed.Path, releasesPath); idx != -1 {
+		var assetPath string
+		var found bool
+		if version, assetPath, found = strings.Cut(parsed.Path[idx+len(releasesPath):], releaseDownloadsPath); !found || strings.Contains(version, "/") {
+			return "", "", false
+		}
+		assetPath = strings.Trim(assetPath, "/")
+		filename = assetPath[strings.LastIndex(assetPath, "/")+1:]
 	}
 
-	parts := strings.Split(after, "/")
-	if len(parts) != 2 {
-		return
-	}
-
-	version = parts[0]
-	filename = parts[1]
 	if version == "" || filename == "" {
-		return
+		return "", "", false
 	}
 
 	return version, filename, true
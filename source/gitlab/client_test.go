@@ -0,0 +1,30 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+func TestNewClientRejectsUnknownMode(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected NewClient to panic on unknown default mode")
+		}
+	}()
+	NewClient("https://gitlab.example", Mode("release"))
+}
+
+func TestUnknownProjectModeIsInvalidSettings(t *testing.T) {
+
+	src := NewClient("https://gitlab.example")
+	project := domain.Project{RepoURL: "https://gitlab.example/group/repo", Settings: map[string]string{SettingMode: "release"}}
+
+	if _, err := src.GetVersions(context.Background(), project); !errors.Is(err, errs.ErrInvalidSourceSettings) {
+		t.Fatalf("expected ErrInvalidSourceSettings, got %v", err)
+	}
+}
@@ -25,9 +25,29 @@ func (s *Source) GetFileStream(ctx context.Context, project domain.Project, vers
 
 func (s *Source) GetFileResponse(ctx context.Context, project domain.Project, version string, filename string) (resp *http.Response, err error) {
 
-	apiURL := s.buildAPIURLForManifest(project.RepoURL, version, filename)
+	return s.GetFileResponseWithHeader(ctx, project, version, filename, nil)
+}
+
+// GetFileResponseWithHeader передаёт Range и условные заголовки в GitLab; 206 и 304 возвращаются как есть.
+func (s *Source) GetFileResponseWithHeader(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	return s.fileResponse(ctx, http.MethodGet, project, version, filename, header)
+}
+
+// HeadFileResponse — HEAD к пакету или вложению релиза, тело не скачивается.
+func (s *Source) HeadFileResponse(ctx context.Context, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	return s.fileResponse(ctx, http.MethodHead, project, version, filename, header)
+}
 
-	slog.Debug("GitLab API request",
+func (s *Source) fileResponse(ctx context.Context, method string, project domain.Project, version string, filename string, header http.Header) (resp *http.Response, err error) {
+
+	var apiURL string
+	if apiURL, err = s.fileURL(ctx, project, version, filename); err != nil {
+		return
+	}
+
+	slog.DebugContext(ctx, "GitLab API request",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 		slog.String(helpers.LogKeySource, sourceName),
 		slog.String(helpers.LogKeyRequestURL, apiURL),
@@ -37,26 +57,19 @@ func (s *Source) GetFileResponse(ctx context.Context, project domain.Project, ve
 	)
 
 	var req *http.Request
-	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, method, apiURL, nil); err != nil {
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set(privateTokenHeader, token) //nolint:canonicalheader
-	}
+	s.setAuth(req, project)
+	helpers.ApplyRequestHeaders(req, header)
 
 	if resp, err = s.http.Do(req); err != nil {
 		return
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Debug("GitLab API error response",
+	if !helpers.IsFileResponseStatus(resp.StatusCode) {
+		slog.DebugContext(ctx, "GitLab API error response",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetFile),
 			slog.String(helpers.LogKeySource, sourceName),
 			slog.String(helpers.LogKeyRequestURL, apiURL),
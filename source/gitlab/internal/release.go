@@ -0,0 +1,16 @@
+package internal
+
+type Release struct {
+	TagName string        `json:"tag_name"`
+	Assets  ReleaseAssets `json:"assets"`
+}
+
+type ReleaseAssets struct {
+	Links []ReleaseLink `json:"links"`
+}
+
+type ReleaseLink struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+}
@@ -2,8 +2,8 @@ package gitlab
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -17,11 +17,38 @@ import (
 	"github.com/seniorGolang/tg-proxy/model/domain"
 )
 
+// manifestNames — имена файла манифеста; manifest.yml, исторический для GitLab, проверяется первым.
+var manifestNames = []string{"manifest.yml", "manifest.yaml"}
+
+// GetManifest ищет манифест под каждым из manifestNames; если нет ни одного, возвращается ошибка последней попытки
+// (404 или errs.ErrFileNotFound), которую движок считает отсутствием версии.
 func (s *Source) GetManifest(ctx context.Context, project domain.Project, version string) (manifest domain.Manifest, err error) {
 
-	apiURL := s.buildAPIURLForManifest(project.RepoURL, version, "manifest.yml")
+	for _, name := range manifestNames {
+		if manifest, err = s.getManifestFile(ctx, project, version, name); !manifestMissing(err) {
+			return
+		}
+	}
+	return
+}
+
+func manifestMissing(err error) (missing bool) {
+
+	if errors.Is(err, errs.ErrFileNotFound) {
+		return true
+	}
+	statusCode, found := helpers.ExtractStatusCode(err)
+	return found && statusCode == http.StatusNotFound
+}
+
+func (s *Source) getManifestFile(ctx context.Context, project domain.Project, version string, name string) (manifest domain.Manifest, err error) {
+
+	var apiURL string
+	if apiURL, err = s.fileURL(ctx, project, version, name); err != nil {
+		return
+	}
 
-	slog.Debug("GitLab API request",
+	slog.DebugContext(ctx, "GitLab API request",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 		slog.String(helpers.LogKeySource, sourceName),
 		slog.String(helpers.LogKeyRequestURL, apiURL),
@@ -34,37 +61,24 @@ func (s *Source) GetManifest(ctx context.Context, project domain.Project, versio
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set(privateTokenHeader, token) //nolint:canonicalheader
-	}
+	s.setAuth(req, project)
 
-	var resp *http.Response
-	if resp, err = s.http.Do(req); err != nil {
+	var statusCode int
+	var data []byte
+	if statusCode, data, err = s.fetch(req); err != nil {
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Debug("GitLab API error response",
+	if statusCode != http.StatusOK {
+		slog.DebugContext(ctx, "GitLab API error response",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetManifest),
 			slog.String(helpers.LogKeySource, sourceName),
 			slog.String(helpers.LogKeyRequestURL, apiURL),
-			slog.Int(helpers.LogKeyStatusCode, resp.StatusCode),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyRepoURL, project.RepoURL),
 			slog.String(helpers.LogKeyVersion, version),
 		)
-		err = fmt.Errorf("%w: status %d", errs.ErrGitLabAPI, resp.StatusCode)
-		return
-	}
-
-	var data []byte
-	if data, err = io.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf("%w: status %d", errs.ErrGitLabAPI, statusCode)
 		return
 	}
 
@@ -78,10 +92,19 @@ func (s *Source) GetManifest(ctx context.Context, project domain.Project, versio
 	return
 }
 
-func (s *Source) buildAPIURLForManifest(repoURL string, version string, filename string) (apiURL string) {
+// fileURL — адрес файла версии: в generic-пакете проекта или, в режиме ModeReleases, по ссылке на ассет релиза.
+func (s *Source) fileURL(ctx context.Context, project domain.Project, version string, filename string) (fileURL string, err error) {
+
+	var mode string
+	if mode, err = s.projectMode(project); err != nil {
+		return
+	}
+	if mode == ModeReleases {
+		return s.releaseAssetURL(ctx, project, version, filename)
+	}
 
-	projectPath := s.extractProjectPath(repoURL)
-	apiURL = s.buildAPIURL("api", "v4", "projects", projectPath, "packages", "generic", "release", version, filename)
+	projectPath := s.extractProjectPath(project.RepoURL)
+	fileURL = s.buildAPIURL("api", "v4", "projects", projectPath, "packages", "generic", s.projectPackageName(project), version, filename)
 	return
 }
 
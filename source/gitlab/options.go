@@ -1,5 +1,15 @@
 package gitlab
 
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/resilience"
+	"github.com/seniorGolang/tg-proxy/tracing"
+)
+
 type ClientOption func(*Source)
 
 func DefaultToken(token string) (opt ClientOption) {
@@ -7,3 +17,44 @@ func DefaultToken(token string) (opt ClientOption) {
 		s.token = token
 	}
 }
+
+// PackageName — имя generic-пакета по умолчанию для проектов без настройки SettingPackageName (по умолчанию release).
+func PackageName(name string) (opt ClientOption) {
+	return func(s *Source) {
+		s.packageName = name
+	}
+}
+
+// Mode — режим по умолчанию для проектов без настройки SettingMode: ModePackages (по умолчанию) или ModeReleases.
+// С другим значением NewClient паникует.
+func Mode(mode string) (opt ClientOption) {
+	return func(s *Source) {
+		s.mode = mode
+	}
+}
+
+// TracerProvider включает клиентские спаны для HTTP-запросов к API и передачу контекста трассы в заголовке traceparent.
+func TracerProvider(tp trace.TracerProvider) (opt ClientOption) {
+	return func(s *Source) {
+		s.http.Transport = tracing.NewTransport(s.http.Transport, tp)
+	}
+}
+
+// Resilience настраивает повторы и circuit breaker запросов к источнику (см. пакет resilience).
+// Без опции действуют значения по умолчанию: два повтора GET и breaker после пяти сбоев подряд.
+func Resilience(opts ...resilience.Option) (opt ClientOption) {
+	return func(s *Source) {
+		s.resilience = append(s.resilience, opts...)
+	}
+}
+
+// ResponseCache сохраняет списки пакетов и манифесты в store вместе с ETag и Last-Modified; повторные запросы
+// идут с If-None-Match, и на 304 тело берётся из store. ttl — срок хранения ответа (0 — значение по умолчанию, сутки).
+func ResponseCache(store helpers.ResponseStore, ttl time.Duration) (opt ClientOption) {
+	return func(s *Source) {
+		s.responses = store
+		if ttl > 0 {
+			s.responseTTL = ttl
+		}
+	}
+}
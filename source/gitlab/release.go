@@ -0,0 +1,112 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/helpers"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+	"github.com/seniorGolang/tg-proxy/source/gitlab/internal"
+)
+
+const releasesPerPage = 100
+
+func (s *Source) listReleaseVersions(ctx context.Context, project domain.Project) (versions []string, err error) {
+
+	projectPath := s.extractProjectPath(project.RepoURL)
+
+	versions = make([]string, 0)
+	for page := 1; ; page++ {
+		apiURL := s.buildAPIURLWithQuery(
+			map[string]string{
+				"page":     strconv.Itoa(page),
+				"per_page": strconv.Itoa(releasesPerPage),
+			},
+			"api", "v4", "projects", projectPath, "releases",
+		)
+
+		var releases []internal.Release
+		if err = s.getJSON(ctx, project, helpers.ActionGetVersions, apiURL, &releases); err != nil {
+			return
+		}
+		for _, release := range releases {
+			if release.TagName != "" {
+				versions = append(versions, release.TagName)
+			}
+		}
+		if len(releases) < releasesPerPage {
+			break
+		}
+	}
+
+	return
+}
+
+// releaseAssetURL ищет ссылку на ассет релиза по имени ссылки или по последнему сегменту её пути.
+// Предпочитается direct_asset_url: он на хосте GitLab и проверяет доступ к приватным ссылкам по токену.
+func (s *Source) releaseAssetURL(ctx context.Context, project domain.Project, version string, filename string) (assetURL string, err error) {
+
+	projectPath := s.extractProjectPath(project.RepoURL)
+	apiURL := s.buildAPIURL("api", "v4", "projects", projectPath, "releases", version)
+
+	var release internal.Release
+	if err = s.getJSON(ctx, project, helpers.ActionGetFile, apiURL, &release); err != nil {
+		return
+	}
+
+	for _, link := range release.Assets.Links {
+		if link.Name != filename && path.Base(link.DirectAssetURL) != filename && path.Base(link.URL) != filename {
+			continue
+		}
+		if assetURL = link.DirectAssetURL; assetURL == "" {
+			assetURL = link.URL
+		}
+		return
+	}
+
+	err = fmt.Errorf("%w: file %s not found in release %s", errs.ErrFileNotFound, filename, version)
+	return
+}
+
+func (s *Source) getJSON(ctx context.Context, project domain.Project, action string, apiURL string, target any) (err error) {
+
+	slog.DebugContext(ctx, "GitLab API request",
+		slog.String(helpers.LogKeyAction, action),
+		slog.String(helpers.LogKeySource, sourceName),
+		slog.String(helpers.LogKeyRequestURL, apiURL),
+		slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+	)
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil); err != nil {
+		return
+	}
+	s.setAuth(req, project)
+	req.Header.Set("Accept", "application/json")
+
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req); err != nil {
+		return
+	}
+
+	if statusCode != http.StatusOK {
+		slog.DebugContext(ctx, "GitLab API error response",
+			slog.String(helpers.LogKeyAction, action),
+			slog.String(helpers.LogKeySource, sourceName),
+			slog.String(helpers.LogKeyRequestURL, apiURL),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
+			slog.String(helpers.LogKeyRepoURL, project.RepoURL),
+		)
+		err = fmt.Errorf("%w: status %d", errs.ErrGitLabAPI, statusCode)
+		return
+	}
+
+	return json.Unmarshal(body, target)
+}
@@ -0,0 +1,121 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/seniorGolang/tg-proxy/errs"
+	"github.com/seniorGolang/tg-proxy/model/domain"
+)
+
+const testToken = "glpat-secret"
+
+// fakeGitLab — GitLab с релизом v1.0.0: ассеты на самом GitLab и на внешнем хранилище,
+// каждый сервер запоминает PRIVATE-TOKEN запросов к файлам.
+type fakeGitLab struct {
+	gitlab   *httptest.Server
+	external *httptest.Server
+	links    []map[string]string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func newFakeGitLab(t *testing.T) (fake *fakeGitLab) {
+
+	fake = &fakeGitLab{tokens: make(map[string]string)}
+	fake.external = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.record("external"+r.URL.Path, r)
+		_, _ = w.Write([]byte("external"))
+	}))
+	t.Cleanup(fake.external.Close)
+
+	fake.gitlab = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.EscapedPath(), "/releases/v1.0.0") {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": "v1.0.0",
+				"assets":   map[string]any{"links": fake.links},
+			})
+			return
+		}
+		fake.record("gitlab"+r.URL.Path, r)
+		if strings.HasSuffix(r.URL.Path, ".yaml") {
+			_, _ = w.Write([]byte("version: v1.0.0\n"))
+			return
+		}
+		_, _ = w.Write([]byte("gitlab"))
+	}))
+	t.Cleanup(fake.gitlab.Close)
+
+	fake.links = []map[string]string{
+		{"name": "own.bin", "url": fake.gitlab.URL + "/group/repo/-/releases/v1.0.0/downloads/own.bin", "direct_asset_url": fake.gitlab.URL + "/group/repo/-/releases/v1.0.0/downloads/own.bin"},
+		{"name": "external.bin", "url": fake.external.URL + "/bucket/external.bin"},
+	}
+	return
+}
+
+func (fake *fakeGitLab) record(key string, r *http.Request) {
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.tokens[key] = r.Header.Get(privateTokenHeader)
+}
+
+func (fake *fakeGitLab) token(key string) (token string, found bool) {
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	token, found = fake.tokens[key]
+	return
+}
+
+func TestReleaseAssetTokenStaysOnGitLabHost(t *testing.T) {
+
+	fake := newFakeGitLab(t)
+	src := NewClient(fake.gitlab.URL, DefaultToken(testToken), Mode(ModeReleases))
+	project := domain.Project{RepoURL: fake.gitlab.URL + "/group/repo"}
+
+	for _, filename := range []string{"own.bin", "external.bin"} {
+		resp, err := src.GetFileResponse(context.Background(), project, "v1.0.0", filename)
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	if token, found := fake.token("gitlab/group/repo/-/releases/v1.0.0/downloads/own.bin"); !found || token != testToken {
+		t.Fatalf("expected token on GitLab asset, got %q (requested: %v)", token, found)
+	}
+	if token, found := fake.token("external/bucket/external.bin"); !found || token != "" {
+		t.Fatalf("expected no token on external asset, got %q (requested: %v)", token, found)
+	}
+}
+
+func TestReleaseManifestNames(t *testing.T) {
+
+	fake := newFakeGitLab(t)
+	src := NewClient(fake.gitlab.URL, DefaultToken(testToken), Mode(ModeReleases))
+	project := domain.Project{RepoURL: fake.gitlab.URL + "/group/repo"}
+
+	// релиз без манифеста — ErrFileNotFound, который движок превращает в отсутствие версии
+	if _, err := src.GetManifest(context.Background(), project, "v1.0.0"); !errors.Is(err, errs.ErrFileNotFound) {
+		t.Fatalf("expected ErrFileNotFound for release without manifest, got %v", err)
+	}
+
+	fake.links = append(fake.links, map[string]string{"name": "manifest.yaml", "direct_asset_url": fake.gitlab.URL + "/group/repo/-/releases/v1.0.0/downloads/manifest.yaml"})
+	manifest, err := src.GetManifest(context.Background(), project, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != "v1.0.0" {
+		t.Fatalf("expected manifest.yaml to be used, got version %q", manifest.Version)
+	}
+}
@@ -15,18 +15,31 @@ import (
 	"github.com/seniorGolang/tg-proxy/source/gitlab/internal"
 )
 
+// GetVersions в режиме ModeReleases берёт теги релизов, иначе — версии generic-пакета проекта.
 func (s *Source) GetVersions(ctx context.Context, project domain.Project) (versions []string, err error) {
 
+	var mode string
+	if mode, err = s.projectMode(project); err != nil {
+		return
+	}
+	if mode == ModeReleases {
+		return s.listReleaseVersions(ctx, project)
+	}
+	return s.listPackageVersions(ctx, project)
+}
+
+func (s *Source) listPackageVersions(ctx context.Context, project domain.Project) (versions []string, err error) {
+
 	projectPath := s.extractProjectPath(project.RepoURL)
 	apiURL := s.buildAPIURLWithQuery(
 		map[string]string{
 			"package_type": "generic",
-			"package_name": "release",
+			"package_name": s.projectPackageName(project),
 		},
 		"api", "v4", "projects", projectPath, "packages",
 	)
 
-	slog.Debug("GitLab API request",
+	slog.DebugContext(ctx, "GitLab API request",
 		slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 		slog.String(helpers.LogKeySource, sourceName),
 		slog.String(helpers.LogKeyRequestURL, apiURL),
@@ -39,37 +52,29 @@ func (s *Source) GetVersions(ctx context.Context, project domain.Project) (versi
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set(privateTokenHeader, token) //nolint:canonicalheader
-	}
+	s.setAuth(req, project)
 
-	var resp *http.Response
-	if resp, err = s.http.Do(req); err != nil {
+	var statusCode int
+	var body []byte
+	if statusCode, body, err = s.fetch(req); err != nil {
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Debug("GitLab API error response",
+	if statusCode != http.StatusOK {
+		slog.DebugContext(ctx, "GitLab API error response",
 			slog.String(helpers.LogKeyAction, helpers.ActionGetVersions),
 			slog.String(helpers.LogKeySource, sourceName),
 			slog.String(helpers.LogKeyRequestURL, apiURL),
-			slog.Int(helpers.LogKeyStatusCode, resp.StatusCode),
+			slog.Int(helpers.LogKeyStatusCode, statusCode),
 			slog.String(helpers.LogKeyRepoURL, project.RepoURL),
 			slog.String("project_path", projectPath),
 		)
-		err = fmt.Errorf("%w: status %d", errs.ErrGitLabAPI, resp.StatusCode)
+		err = fmt.Errorf("%w: status %d", errs.ErrGitLabAPI, statusCode)
 		return
 	}
 
 	var packages []internal.Package
-	if err = json.NewDecoder(resp.Body).Decode(&packages); err != nil {
+	if err = json.Unmarshal(body, &packages); err != nil {
 		return
 	}
 
@@ -194,7 +199,7 @@ func (s *Source) ValidateProject(ctx context.Context, project domain.Project) (e
 	projectPath := s.extractProjectPath(project.RepoURL)
 	apiURL := s.buildAPIURL("api", "v4", "projects", projectPath)
 
-	slog.Debug("GitLab API request",
+	slog.DebugContext(ctx, "GitLab API request",
 		slog.String(helpers.LogKeyAction, "validate_project"),
 		slog.String(helpers.LogKeySource, sourceName),
 		slog.String(helpers.LogKeyRequestURL, apiURL),
@@ -207,15 +212,7 @@ func (s *Source) ValidateProject(ctx context.Context, project domain.Project) (e
 		return
 	}
 
-	var token string
-	if project.Token != "" {
-		token = project.Token
-	} else {
-		token = s.token
-	}
-	if token != "" {
-		req.Header.Set(privateTokenHeader, token) //nolint:canonicalheader
-	}
+	s.setAuth(req, project)
 
 	var resp *http.Response
 	if resp, err = s.http.Do(req); err != nil {
@@ -224,7 +221,7 @@ func (s *Source) ValidateProject(ctx context.Context, project domain.Project) (e
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		slog.Debug("GitLab API error response",
+		slog.DebugContext(ctx, "GitLab API error response",
 			slog.String(helpers.LogKeyAction, "validate_project"),
 			slog.String(helpers.LogKeySource, sourceName),
 			slog.String(helpers.LogKeyRequestURL, apiURL),
@@ -14,6 +14,7 @@ var Project = struct {
 	EncryptedToken field.String
 	Description    field.String
 	SourceName     field.String
+	Settings       field.Field[any]
 	CreatedAt      field.Time
 	UpdatedAt      field.Time
 }{
@@ -23,6 +24,7 @@ var Project = struct {
 	EncryptedToken: field.String{}.WithColumn("encrypted_token"),
 	Description:    field.String{}.WithColumn("description"),
 	SourceName:     field.String{}.WithColumn("source_name"),
+	Settings:       field.Field[any]{}.WithColumn("settings"),
 	CreatedAt:      field.Time{}.WithColumn("created_at"),
 	UpdatedAt:      field.Time{}.WithColumn("updated_at"),
 }
@@ -11,14 +11,15 @@ import (
 )
 
 type Project struct {
-	ID             uuid.UUID `gorm:"type:uuid;primaryKey;column:id"`
-	Alias          string    `gorm:"column:alias;not null;uniqueIndex:idx_projects_alias"`
-	RepoURL        string    `gorm:"column:repo_url;not null;index:idx_projects_repo_url"`
-	EncryptedToken string    `gorm:"column:encrypted_token"`
-	Description    string    `gorm:"column:description"`
-	SourceName     string    `gorm:"column:source_name;index:idx_projects_source_name"`
-	CreatedAt      time.Time `gorm:"column:created_at;not null;index:idx_projects_created_at,sort:desc"`
-	UpdatedAt      time.Time `gorm:"column:updated_at;not null"`
+	ID             uuid.UUID         `gorm:"type:uuid;primaryKey;column:id"`
+	Alias          string            `gorm:"column:alias;not null;uniqueIndex:idx_projects_alias"`
+	RepoURL        string            `gorm:"column:repo_url;not null;index:idx_projects_repo_url"`
+	EncryptedToken string            `gorm:"column:encrypted_token"`
+	Description    string            `gorm:"column:description"`
+	SourceName     string            `gorm:"column:source_name;index:idx_projects_source_name"`
+	Settings       map[string]string `gorm:"column:settings;type:text;serializer:json"`
+	CreatedAt      time.Time         `gorm:"column:created_at;not null;index:idx_projects_created_at,sort:desc"`
+	UpdatedAt      time.Time         `gorm:"column:updated_at;not null"`
 }
 
 func (Project) TableName() string {
@@ -33,6 +34,7 @@ func (p Project) ToDomain() (project domain.Project) {
 		EncryptedToken: p.EncryptedToken,
 		Description:    p.Description,
 		SourceName:     p.SourceName,
+		Settings:       p.Settings,
 		CreatedAt:      p.CreatedAt,
 		UpdatedAt:      p.UpdatedAt,
 	}
@@ -46,6 +48,7 @@ func FromDomain(project domain.Project) (p Project) {
 		EncryptedToken: project.EncryptedToken,
 		Description:    project.Description,
 		SourceName:     project.SourceName,
+		Settings:       project.Settings,
 		CreatedAt:      project.CreatedAt,
 		UpdatedAt:      project.UpdatedAt,
 	}
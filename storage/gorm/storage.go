@@ -84,6 +84,16 @@ func NewRepository(dialector gorm.Dialector, config *gorm.Config, opts ...Option
 	return
 }
 
+// Ping проверяет соединение с базой; используется проверкой готовности прокси.
+func (s *Storage) Ping(ctx context.Context) (err error) {
+
+	var sqlDB *sql.DB
+	if sqlDB, err = s.db.DB(); err != nil {
+		return
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 func (s *Storage) initSchema(ctx context.Context) (err error) {
 
 	if err = s.db.WithContext(ctx).Table(s.projectsTable).AutoMigrate(&Project{}); err != nil {
@@ -7,22 +7,24 @@ import (
 )
 
 type ProjectDocument struct {
-	ID             uuid.UUID `bson:"_id"`
-	Alias          string    `bson:"alias"`
-	RepoURL        string    `bson:"repo_url"`
-	EncryptedToken string    `bson:"encrypted_token,omitempty"`
-	Description    string    `bson:"description,omitempty"`
-	SourceName     string    `bson:"source_name,omitempty"`
-	CreatedAt      time.Time `bson:"created_at"`
-	UpdatedAt      time.Time `bson:"updated_at"`
+	ID             uuid.UUID         `bson:"_id"`
+	Alias          string            `bson:"alias"`
+	RepoURL        string            `bson:"repo_url"`
+	EncryptedToken string            `bson:"encrypted_token,omitempty"`
+	Description    string            `bson:"description,omitempty"`
+	SourceName     string            `bson:"source_name,omitempty"`
+	Settings       map[string]string `bson:"settings,omitempty"`
+	CreatedAt      time.Time         `bson:"created_at"`
+	UpdatedAt      time.Time         `bson:"updated_at"`
 }
 
 type ProjectUpdateDocument struct {
-	RepoURL        string    `bson:"repo_url"`
-	EncryptedToken string    `bson:"encrypted_token,omitempty"`
-	Description    string    `bson:"description,omitempty"`
-	SourceName     string    `bson:"source_name,omitempty"`
-	UpdatedAt      time.Time `bson:"updated_at"`
+	RepoURL        string            `bson:"repo_url"`
+	EncryptedToken string            `bson:"encrypted_token,omitempty"`
+	Description    string            `bson:"description,omitempty"`
+	SourceName     string            `bson:"source_name,omitempty"`
+	Settings       map[string]string `bson:"settings"`
+	UpdatedAt      time.Time         `bson:"updated_at"`
 }
 
 type CatalogVersionDocument struct {
@@ -13,6 +13,7 @@ func toDocument(project domain.Project) (doc internal.ProjectDocument) {
 		EncryptedToken: project.EncryptedToken,
 		Description:    project.Description,
 		SourceName:     project.SourceName,
+		Settings:       project.Settings,
 		CreatedAt:      project.CreatedAt,
 		UpdatedAt:      project.UpdatedAt,
 	}
@@ -26,6 +27,7 @@ func toDomain(doc internal.ProjectDocument) (project domain.Project) {
 		EncryptedToken: doc.EncryptedToken,
 		Description:    doc.Description,
 		SourceName:     doc.SourceName,
+		Settings:       doc.Settings,
 		CreatedAt:      doc.CreatedAt,
 		UpdatedAt:      doc.UpdatedAt,
 	}
@@ -37,6 +39,7 @@ func toUpdateDocument(project domain.Project) (doc internal.ProjectUpdateDocumen
 		EncryptedToken: project.EncryptedToken,
 		Description:    project.Description,
 		SourceName:     project.SourceName,
+		Settings:       project.Settings,
 		UpdatedAt:      project.UpdatedAt,
 	}
 }
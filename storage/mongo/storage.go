@@ -85,3 +85,9 @@ func NewRepository(uri string, opts ...Option) (stor *Storage, err error) {
 
 	return
 }
+
+// Ping проверяет соединение с MongoDB; используется проверкой готовности прокси.
+func (s *Storage) Ping(ctx context.Context) (err error) {
+
+	return s.client.Ping(ctx, nil)
+}
@@ -0,0 +1,109 @@
+package tgproxy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/tg-proxy/tracing"
+)
+
+// fiberHeaderCarrier читает заголовки fasthttp-запроса для извлечения traceparent.
+type fiberHeaderCarrier struct {
+	c *fiber.Ctx
+}
+
+// TracerProvider включает серверные спаны для входящих запросов; контекст трассы продолжается из заголовка traceparent.
+// Тот же провайдер стоит передать в core.TracerProvider, чтобы спаны движка стали дочерними.
+func TracerProvider(tp trace.TracerProvider) (opt ProxyOption) {
+	return func(p *Proxy) {
+		p.tracer = tracing.Tracer(tp)
+	}
+}
+
+func (p *Proxy) tracingMiddleware(next http.HandlerFunc) (handler http.HandlerFunc) {
+
+	if p.tracer == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := r.Pattern
+		if _, pattern, found := strings.Cut(route, " "); found {
+			route = pattern
+		}
+		ctx := tracing.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := p.tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		mw := &statusWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next(mw, r.WithContext(ctx))
+		setSpanStatus(span, mw.statusCode)
+	}
+}
+
+func (p *Proxy) tracingFiberMiddleware(c *fiber.Ctx) (err error) {
+
+	if p.tracer == nil {
+		return c.Next()
+	}
+
+	ctx := tracing.Propagator.Extract(c.UserContext(), fiberHeaderCarrier{c: c})
+	ctx, span := p.tracer.Start(ctx, c.Method(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", c.Method()),
+			attribute.String("url.path", c.Path()),
+		),
+	)
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	err = c.Next()
+
+	// маршрут известен только после сопоставления обработчика
+	route := c.Route().Path
+	span.SetName(c.Method() + " " + route)
+	span.SetAttributes(attribute.String("http.route", route))
+
+	setSpanStatus(span, fiberStatusCode(c, err))
+	return
+}
+
+func setSpanStatus(span trace.Span, statusCode int) {
+
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	if statusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+}
+
+func (carrier fiberHeaderCarrier) Get(key string) (value string) {
+
+	return carrier.c.Get(key)
+}
+
+func (carrier fiberHeaderCarrier) Set(key string, value string) {
+
+	carrier.c.Request().Header.Set(key, value)
+}
+
+func (carrier fiberHeaderCarrier) Keys() (keys []string) {
+
+	for key := range carrier.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// InstrumentationName — имя трассировщика, под которым прокси, движок и источники создают спаны.
+const InstrumentationName = "github.com/seniorGolang/tg-proxy"
+
+// Propagator — W3C Trace Context: входящий traceparent продолжает трассу, исходящие запросы к источникам его получают.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// NewProvider создаёт провайдер, отправляющий спаны в exporter пачками. Экспортер подключаемый:
+// подойдёт любой sdktrace.SpanExporter (OTLP, stdout и т.п.).
+func NewProvider(exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) (tp *sdktrace.TracerProvider) {
+
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithBatcher(exporter)}, opts...)...)
+}
+
+// NewInMemoryProvider — провайдер для тестов: спаны синхронно складываются в память и читаются через exporter.GetSpans.
+func NewInMemoryProvider() (tp *sdktrace.TracerProvider, exporter *tracetest.InMemoryExporter) {
+
+	exporter = tracetest.NewInMemoryExporter()
+	tp = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return
+}
+
+// Tracer возвращает трассировщик провайдера; без провайдера — no-op, спаны не создаются.
+func Tracer(tp trace.TracerProvider) (tracer trace.Tracer) {
+
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(InstrumentationName)
+}
+
+// End завершает спан, отмечая ошибку.
+func End(span trace.Span, err error) {
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
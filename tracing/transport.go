@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// transport создаёт клиентский спан на каждый исходящий запрос и передаёт контекст трассы в заголовке traceparent.
+type transport struct {
+	base   http.RoundTripper
+	tracer trace.Tracer
+}
+
+func NewTransport(base http.RoundTripper, tp trace.TracerProvider) (rt http.RoundTripper) {
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, tracer: Tracer(tp)}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	Propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	if resp, err = t.base.RoundTrip(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	}
+	return
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/seniorGolang/tg-proxy/helpers"
 	"github.com/seniorGolang/tg-proxy/model"
 )
 
@@ -210,6 +211,9 @@ func (ui *UI) serveVersions(ctx context.Context, alias string) (html []byte, err
 	if err != nil {
 		return nil, err
 	}
+	// провайдер может отдавать срез из кеша — сортируем копию
+	versions = append([]string(nil), versions...)
+	helpers.SortVersions(versions)
 	data := versionsData{UIPrefix: ui.uiPrefix, Alias: alias, Versions: versions}
 	return ui.renderTemplate("versions", data)
 }